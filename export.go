@@ -6,35 +6,110 @@ package main
  #include <stdio.h>
  #include <errno.h>
  #include <stdlib.h>
+
+ // flags selecting the NBT format
+ #define NBT_FLAG_BEDROCK 1
+ #define NBT_FLAG_NETWORK 2
+ #define NBT_FLAG_NAMELESS_ROOT 4
+ // accept and ignore data after the root tag when parsing
+ #define NBT_FLAG_ALLOW_TRAILING 8
+ // return whatever could be parsed from corrupt input instead of an error, as
+ // {"tag": <the tree, or null>, "errors": [{"message": ..., "offset": ..., "path": ...}, ...]}
+ // where errors is empty if the input was fine
+ #define NBT_FLAG_LENIENT 16
+ // parse root tags stored back to back and return them as a JSON array
+ #define NBT_FLAG_SEQUENCE 32
+ // accept root tags other than compounds and lists
+ #define NBT_FLAG_SCALAR_ROOT 64
 */
 import "C"
 import (
-	"bytes"
 	"encoding/json"
-	"goNbt/lib"
+	"errors"
 	"goNbt/lib/nbt"
 	"unsafe"
 )
 
+// encodingFromFlags maps NBT_FLAG_* values to an encoding; NBT_FLAG_NETWORK implies Bedrock
+func encodingFromFlags(flags C.int) nbt.Encoding {
+	if flags&C.NBT_FLAG_NETWORK != 0 {
+		return nbt.EncodingBedrockNetwork
+	}
+	if flags&C.NBT_FLAG_BEDROCK != 0 {
+		return nbt.EncodingBedrock
+	}
+	return nbt.EncodingJava
+}
+
+func parseOptionsFromFlags(flags C.int) nbt.ParseOptions {
+	opts := nbt.ParseOptions{
+		Encoding:     encodingFromFlags(flags),
+		NamelessRoot: flags&C.NBT_FLAG_NAMELESS_ROOT != 0,
+		// gzip and zlib input is detected and decompressed
+		Compression: nbt.CompressionAuto,
+	}
+	if flags&C.NBT_FLAG_ALLOW_TRAILING != 0 {
+		opts.TrailingData = nbt.TrailingDataIgnore
+	}
+	opts.Lenient = flags&C.NBT_FLAG_LENIENT != 0
+	opts.AllowScalarRoot = flags&C.NBT_FLAG_SCALAR_ROOT != 0
+	return opts
+}
+
+// lenientResult is what ParseNBT returns with NBT_FLAG_LENIENT
+type lenientResult struct {
+	Tag    nbt.NBTTag   `json:"tag"`
+	Errors []parseError `json:"errors"`
+}
+
+type parseError struct {
+	Message string `json:"message"`
+	// Offset is the byte offset in the decompressed input
+	Offset int64  `json:"offset"`
+	Path   string `json:"path"`
+}
+
+func newLenientResult(tag nbt.NBTTag, err error) lenientResult {
+	result := lenientResult{Tag: tag, Errors: []parseError{}}
+	var parseErrors nbt.ParseErrors
+	if errors.As(err, &parseErrors) {
+		for _, parseErr := range parseErrors {
+			result.Errors = append(result.Errors, parseError{parseErr.Error(), parseErr.Offset(), parseErr.Path()})
+		}
+	} else if err != nil {
+		result.Errors = append(result.Errors, parseError{Message: err.Error()})
+	}
+	return result
+}
+
 // ParseNBT parses NBT binary data and returns JSON string
 //
 //export ParseNBT
-func ParseNBT(data *C.char, length C.int, isBedrock C.int) *C.char {
+func ParseNBT(data *C.char, length C.int, flags C.int) *C.char {
 	goData := C.GoBytes(unsafe.Pointer(data), length)
 
-	// Unzip if needed
-	unzippedData, err := lib.UnzipReader(bytes.NewReader(goData))
-	if err != nil {
-		// If unzip fails, try to parse as-is
-		unzippedData = goData
-	}
-
-	tag, err := nbt.ParseNBT(unzippedData, isBedrock != 0)
-	if err != nil {
-		return C.CString("ERROR: " + err.Error())
+	var result any
+	var err error
+	if flags&C.NBT_FLAG_SEQUENCE != 0 {
+		// trailing data is governed by NBT_FLAG_ALLOW_TRAILING
+		result, _, err = nbt.ParseNBTSequence(goData, parseOptionsFromFlags(flags))
+		if err != nil {
+			return C.CString("ERROR: " + err.Error())
+		}
+	} else {
+		var tag nbt.NBTTag
+		tag, err = nbt.ParseNBTWithOptions(goData, parseOptionsFromFlags(flags))
+		if flags&C.NBT_FLAG_LENIENT != 0 {
+			// a partial tree comes with its errors
+			result = newLenientResult(tag, err)
+		} else if err != nil {
+			return C.CString("ERROR: " + err.Error())
+		} else {
+			result = tag
+		}
 	}
 
-	jsonBytes, err := json.MarshalIndent(tag, "", "  ")
+	jsonBytes, err := json.MarshalIndent(result, "", "  ")
 	if err != nil {
 		return C.CString("ERROR: " + err.Error())
 	}
@@ -46,6 +121,14 @@ func ParseNBT(data *C.char, length C.int, isBedrock C.int) *C.char {
 //
 //export SerializeNBT
 func SerializeNBT(jsonData *C.char, compress *C.char, outLength *C.int) *C.char {
+	return SerializeNBTWithFlags(jsonData, compress, 0, outLength)
+}
+
+// SerializeNBTWithFlags is SerializeNBT in the format selected by NBT_FLAG_BEDROCK,
+// NBT_FLAG_NETWORK and NBT_FLAG_NAMELESS_ROOT
+//
+//export SerializeNBTWithFlags
+func SerializeNBTWithFlags(jsonData *C.char, compress *C.char, flags C.int, outLength *C.int) *C.char {
 	goJSON := C.GoString(jsonData)
 	compressType := C.GoString(compress)
 
@@ -56,28 +139,20 @@ func SerializeNBT(jsonData *C.char, compress *C.char, outLength *C.int) *C.char
 		return C.CString("ERROR: " + err.Error())
 	}
 
-	serializedBytes, err := nbt.SerializeTag(&tag, false)
+	opts := nbt.SerializeOptions{
+		Encoding:     encodingFromFlags(flags),
+		NamelessRoot: flags&C.NBT_FLAG_NAMELESS_ROOT != 0,
+	}
+	// anything but gzip and zlib has always meant uncompressed output
+	if compressType == string(nbt.CompressionGzip) || compressType == string(nbt.CompressionZlib) {
+		opts.Compression = nbt.Compression(compressType)
+	}
+	serializedBytes, err := nbt.SerializeNBTWithOptions(&tag, opts)
 	if err != nil {
 		*outLength = 0
 		return C.CString("ERROR: " + err.Error())
 	}
 
-	// Apply compression if requested
-	switch compressType {
-	case "gzip":
-		serializedBytes, err = lib.ZipToGzip(serializedBytes)
-		if err != nil {
-			*outLength = 0
-			return C.CString("ERROR: " + err.Error())
-		}
-	case "zlib":
-		serializedBytes, err = lib.ZipToZlib(serializedBytes)
-		if err != nil {
-			*outLength = 0
-			return C.CString("ERROR: " + err.Error())
-		}
-	}
-
 	*outLength = C.int(len(serializedBytes))
 
 	// Allocate C memory for binary data
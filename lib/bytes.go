@@ -73,6 +73,25 @@ func ZipToZlib(data []byte) ([]byte, error) {
 	return buf.Bytes(), nil
 }
 
+type nopWriteCloser struct {
+	io.Writer
+}
+
+func (nopWriteCloser) Close() error { return nil }
+
+// NewZipWriter returns a writer compressing into w with the given method ("gzip" or "zlib").
+// Any other method writes through uncompressed. Close must be called to flush the compressed stream.
+func NewZipWriter(w io.Writer, method string) io.WriteCloser {
+	switch method {
+	case "gzip":
+		return gzip.NewWriter(w)
+	case "zlib":
+		return zlib.NewWriter(w)
+	default:
+		return nopWriteCloser{w}
+	}
+}
+
 func Reset(reader *bufio.Reader, bytes []byte) io.Reader {
 	return io.MultiReader(bytesReader(bytes), reader)
 }
@@ -171,39 +190,41 @@ func BytesToUInt64(bytes []byte, bigEndian bool) (uint64, error) {
 	return binary.LittleEndian.Uint64(bytes), nil
 }
 
-func UnzipReader(reader io.Reader) ([]byte, error) {
-	magicBytes := make([]byte, 2)
-	n, err := reader.Read(magicBytes)
+// NewUnzipReader returns a reader that decompresses gzip or zlib input on the fly,
+// passing uncompressed input through unchanged
+func NewUnzipReader(reader io.Reader) (io.ReadCloser, error) {
+	buffered := bufio.NewReader(reader)
+	magicBytes, err := buffered.Peek(2)
 	if err != nil {
+		if err == io.EOF && len(magicBytes) > 0 {
+			return nil, io.ErrUnexpectedEOF
+		}
 		return nil, err
 	}
-	if n < 2 {
-		return nil, io.ErrUnexpectedEOF
-	}
 	if magicBytes[0] != 0x1f || magicBytes[1] != 0x8b {
 		// check if it's a zlib format
 		if magicBytes[0] == 0x78 && (magicBytes[1] == 0x01 || magicBytes[1] == 0x5e || magicBytes[1] == 0x9c || magicBytes[1] == 0xda) {
-			combinedReader := Reset(bufio.NewReader(reader), magicBytes)
-			zlibReader, err := zlib.NewReader(combinedReader)
-			if err != nil {
-				return nil, err
-			}
-			defer zlibReader.Close()
-			return io.ReadAll(zlibReader)
+			return zlib.NewReader(buffered)
 		}
 
 		// Not compressed, return the original data
-		combinedReader := Reset(bufio.NewReader(reader), magicBytes)
-		return io.ReadAll(combinedReader)
+		return io.NopCloser(buffered), nil
 	}
 	// It's gzip format
-	reader = Reset(bufio.NewReader(reader), magicBytes)
-	gzipReader, err := gzip.NewReader(reader)
+	gzipReader, err := gzip.NewReader(buffered)
+	if err != nil {
+		return nil, err
+	}
+	return gzipReader, nil
+}
+
+func UnzipReader(reader io.Reader) ([]byte, error) {
+	unzipReader, err := NewUnzipReader(reader)
 	if err != nil {
 		return nil, err
 	}
-	defer gzipReader.Close()
-	return io.ReadAll(gzipReader)
+	defer unzipReader.Close()
+	return io.ReadAll(unzipReader)
 }
 
 // UInt16ToBytes converts a uint16 to a byte slice in big-endian or little-endian format
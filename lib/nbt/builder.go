@@ -0,0 +1,168 @@
+package nbt
+
+import "fmt"
+
+// The constructors below build tags outside of parsing.
+// List elements are written without their names, so they are usually created with an empty name.
+
+func NewByte(name string, value byte) *TagByte {
+	return &TagByte{baseTag{BTagByte, name}, value}
+}
+
+func NewShort(name string, value int16) *TagShort {
+	return &TagShort{baseTag{BTagShort, name}, value}
+}
+
+func NewInt(name string, value int32) *TagInt {
+	return &TagInt{baseTag{BTagInt, name}, value}
+}
+
+func NewLong(name string, value int64) *TagLong {
+	return &TagLong{baseTag{BTagLong, name}, value}
+}
+
+func NewFloat(name string, value float32) *TagFloat {
+	return &TagFloat{baseTag{BTagFloat, name}, value}
+}
+
+func NewDouble(name string, value float64) *TagDouble {
+	return &TagDouble{baseTag{BTagDouble, name}, value}
+}
+
+func NewString(name string, value string) *TagString {
+	return &TagString{baseTag{BTagString, name}, value}
+}
+
+func NewByteArray(name string, value []byte) *TagByteArray {
+	return &TagByteArray{baseTag{BTagByteArray, name}, value}
+}
+
+func NewIntArray(name string, value []int32) *TagIntArray {
+	return &TagIntArray{baseTag{BTagIntArray, name}, value}
+}
+
+func NewLongArray(name string, value []int64) *TagLongArray {
+	return &TagLongArray{baseTag{BTagLongArray, name}, value}
+}
+
+func NewEnd() *TagEnd {
+	return &TagEnd{baseTag{BTagEnd, ""}}
+}
+
+// NewList returns a list of elementType holding items.
+// It panics if an item is of another type, since such a list cannot be serialized.
+func NewList(name string, elementType tagTypeByte, items ...NBTTag) *TagList {
+	for i, item := range items {
+		if item.Type() != elementType {
+			panic(fmt.Sprintf("nbt: list element %d is a %s, expected %s", i, TagName[item.Type()], TagName[elementType]))
+		}
+	}
+	return &TagList{baseTag: baseTag{BTagList, name}, ElementType: elementType, Value: items}
+}
+
+// NewCompound returns a compound holding children, followed by the TAG_End that closes it.
+// A TAG_End among children is dropped.
+func NewCompound(name string, children ...NBTTag) *TagCompound {
+	value := make([]NBTTag, 0, len(children)+1)
+	for _, child := range children {
+		if child.Type() != BTagEnd {
+			value = append(value, child)
+		}
+	}
+	return &TagCompound{baseTag: baseTag{BTagCompound, name}, Value: append(value, NewEnd())}
+}
+
+// CompoundBuilder builds a tree of compounds by chaining calls:
+//
+//	root := nbt.NewCompoundBuilder("").
+//		String("id", "minecraft:stone").
+//		Byte("Count", 1).
+//		Compound("tag").
+//		Int("Damage", 0).
+//		End().
+//		Build()
+type CompoundBuilder struct {
+	tag    *TagCompound
+	parent *CompoundBuilder
+}
+
+// NewCompoundBuilder starts building a root compound with the given name
+func NewCompoundBuilder(name string) *CompoundBuilder {
+	return &CompoundBuilder{tag: NewCompound(name)}
+}
+
+func (b *CompoundBuilder) Byte(name string, value byte) *CompoundBuilder {
+	return b.Tag(NewByte(name, value))
+}
+
+func (b *CompoundBuilder) Short(name string, value int16) *CompoundBuilder {
+	return b.Tag(NewShort(name, value))
+}
+
+func (b *CompoundBuilder) Int(name string, value int32) *CompoundBuilder {
+	return b.Tag(NewInt(name, value))
+}
+
+func (b *CompoundBuilder) Long(name string, value int64) *CompoundBuilder {
+	return b.Tag(NewLong(name, value))
+}
+
+func (b *CompoundBuilder) Float(name string, value float32) *CompoundBuilder {
+	return b.Tag(NewFloat(name, value))
+}
+
+func (b *CompoundBuilder) Double(name string, value float64) *CompoundBuilder {
+	return b.Tag(NewDouble(name, value))
+}
+
+func (b *CompoundBuilder) String(name string, value string) *CompoundBuilder {
+	return b.Tag(NewString(name, value))
+}
+
+func (b *CompoundBuilder) ByteArray(name string, value []byte) *CompoundBuilder {
+	return b.Tag(NewByteArray(name, value))
+}
+
+func (b *CompoundBuilder) IntArray(name string, value []int32) *CompoundBuilder {
+	return b.Tag(NewIntArray(name, value))
+}
+
+func (b *CompoundBuilder) LongArray(name string, value []int64) *CompoundBuilder {
+	return b.Tag(NewLongArray(name, value))
+}
+
+// List adds a list, see NewList
+func (b *CompoundBuilder) List(name string, elementType tagTypeByte, items ...NBTTag) *CompoundBuilder {
+	return b.Tag(NewList(name, elementType, items...))
+}
+
+// Tag adds an existing tag, such as one created by a constructor.
+// Like TagCompound.Set, it replaces an earlier tag of the same name.
+func (b *CompoundBuilder) Tag(tag NBTTag) *CompoundBuilder {
+	b.tag.Set(tag)
+	return b
+}
+
+// Compound adds a nested compound and returns the builder for it, until the matching End
+func (b *CompoundBuilder) Compound(name string) *CompoundBuilder {
+	child := NewCompound(name)
+	b.tag.Set(child)
+	return &CompoundBuilder{tag: child, parent: b}
+}
+
+// End closes a compound opened by Compound and returns the builder of its parent.
+// It panics on the root builder.
+func (b *CompoundBuilder) End() *CompoundBuilder {
+	if b.parent == nil {
+		panic("nbt: End called on the root compound builder")
+	}
+	return b.parent
+}
+
+// Build returns the root compound, closing any compounds still open
+func (b *CompoundBuilder) Build() *TagCompound {
+	for b.parent != nil {
+		b = b.parent
+	}
+	return b.tag
+}
@@ -0,0 +1,79 @@
+package nbt
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestConstructorsSerialize(t *testing.T) {
+	root := NewCompound("root",
+		NewString("title", "Stream"),
+		NewList("numbers", BTagInt, NewInt("", 7), NewInt("", 8)),
+		NewByteArray("bytes", []byte{1, 2, 3}),
+	)
+	serialized, err := SerializeTag(root, false)
+	if err != nil {
+		t.Fatalf("Failed to serialize constructed tags: %v", err)
+	}
+	if expected := decoderTestData(); !bytes.Equal(serialized, expected) {
+		t.Errorf("Serialized mismatch.\nGot:      % x\nExpected: % x", serialized, expected)
+	}
+}
+
+func TestNewCompoundSingleEnd(t *testing.T) {
+	compound := NewCompound("", NewInt("a", 1), NewEnd())
+	if len(compound.Value) != 2 || compound.Value[1].Type() != BTagEnd {
+		t.Errorf("Expected one child followed by a single TAG_End, got %v", compound.Value)
+	}
+}
+
+func TestNewListRejectsMixedTypes(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Errorf("Expected NewList to panic on a mismatched element")
+		}
+	}()
+	NewList("mixed", BTagInt, NewInt("", 1), NewLong("", 2))
+}
+
+func TestCompoundBuilder(t *testing.T) {
+	root := NewCompoundBuilder("root").
+		String("title", "Stream").
+		List("numbers", BTagInt, NewInt("", 7), NewInt("", 8)).
+		ByteArray("bytes", []byte{1, 2, 3}).
+		Build()
+	serialized, err := SerializeTag(root, false)
+	if err != nil {
+		t.Fatalf("Failed to serialize built tags: %v", err)
+	}
+	if expected := decoderTestData(); !bytes.Equal(serialized, expected) {
+		t.Errorf("Serialized mismatch.\nGot:      % x\nExpected: % x", serialized, expected)
+	}
+}
+
+func TestCompoundBuilderNesting(t *testing.T) {
+	root := NewCompoundBuilder("").
+		Compound("Level").
+		Compound("Data").
+		Long("Time", 42).
+		End().
+		Short("Version", 3).
+		Build()
+
+	// a round trip through the parser gives the same tree
+	serialized, err := SerializeNBT(root, false)
+	if err != nil {
+		t.Fatalf("Failed to serialize built tags: %v", err)
+	}
+	parsed, parseErr := ParseNBT(serialized, false)
+	if parseErr != nil {
+		t.Fatalf("Failed to parse built tags: %v", parseErr)
+	}
+	level := parsed.(*TagCompound).Value[0].(*TagCompound)
+	if len(level.Value) != 3 || level.Value[1].Name() != "Version" {
+		t.Fatalf("Expected Level to hold Data, Version and TAG_End, got %v", level.Value)
+	}
+	if time := level.Value[0].(*TagCompound).Value[0].(*TagLong); time.Value != 42 {
+		t.Errorf("Expected Level.Data.Time 42, got %d", time.Value)
+	}
+}
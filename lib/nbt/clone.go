@@ -0,0 +1,46 @@
+package nbt
+
+import "slices"
+
+// Clone returns a deep copy of tag, sharing no memory with it,
+// so that the copy can be changed or stored in another tree on its own.
+// Byte arrays are copied too, detaching them from the input they were parsed from.
+func Clone(tag NBTTag) NBTTag {
+	switch t := tag.(type) {
+	case *TagByte:
+		return NewByte(t.name, t.Value)
+	case *TagShort:
+		return NewShort(t.name, t.Value)
+	case *TagInt:
+		return NewInt(t.name, t.Value)
+	case *TagLong:
+		return NewLong(t.name, t.Value)
+	case *TagFloat:
+		return NewFloat(t.name, t.Value)
+	case *TagDouble:
+		return NewDouble(t.name, t.Value)
+	case *TagString:
+		return NewString(t.name, t.Value)
+	case *TagByteArray:
+		return NewByteArray(t.name, slices.Clone(t.Value))
+	case *TagIntArray:
+		return NewIntArray(t.name, slices.Clone(t.Value))
+	case *TagLongArray:
+		return NewLongArray(t.name, slices.Clone(t.Value))
+	case *TagList:
+		items := make([]NBTTag, len(t.Value))
+		for i, item := range t.Value {
+			items[i] = Clone(item)
+		}
+		return &TagList{baseTag: t.baseTag, ElementType: t.ElementType, Value: items, Truncated: t.Truncated}
+	case *TagCompound:
+		children := make([]NBTTag, len(t.Value))
+		for i, child := range t.Value {
+			children[i] = Clone(child)
+		}
+		return &TagCompound{baseTag: t.baseTag, Value: children, Truncated: t.Truncated}
+	case *TagEnd:
+		return &TagEnd{baseTag: t.baseTag}
+	}
+	return tag
+}
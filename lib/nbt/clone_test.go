@@ -0,0 +1,58 @@
+package nbt
+
+import "testing"
+
+func TestCloneDetachesByteArrays(t *testing.T) {
+	data := decoderTestData()
+	tag, err := ParseNBT(data, false)
+	if err != nil {
+		t.Fatalf("Failed to parse NBT: %v", err)
+	}
+	bytes := tag.(*TagCompound).Get("bytes").(*TagByteArray)
+
+	// parsed byte arrays alias the input
+	bytes.Value[0] = 9
+	if data[len(data)-4] != 9 {
+		t.Fatalf("Expected the parsed byte array to alias the input")
+	}
+
+	clone := Clone(tag)
+	if !Equal(tag, clone, EqualOptions{}) {
+		t.Fatalf("Expected the clone to equal the original")
+	}
+	clone.(*TagCompound).Get("bytes").(*TagByteArray).Value[0] = 1
+	if data[len(data)-4] != 9 || bytes.Value[0] != 9 {
+		t.Errorf("Expected the cloned byte array to have its own memory")
+	}
+}
+
+func TestCloneAllTypes(t *testing.T) {
+	original := mustParseSNBT(t, `{b: 1b, s: 2s, i: 3, l: 4L, f: 5f, d: 6d, str: "x",
+		ba: [B; 1b], ia: [I; 2], la: [L; 3L], list: [{n: 1}], c: {n: 2}}`)
+	original.SetName("root")
+	clone := Clone(original)
+	if !Equal(original, clone, EqualOptions{}) {
+		t.Fatalf("Expected the clone to equal the original:\n%s\n%s", FormatSNBT(original), FormatSNBT(clone))
+	}
+
+	for _, path := range []string{"b", "s", "i", "l", "f", "d", "str", "ba[0]", "ia[0]", "la[0]", "list[0].n", "c.n"} {
+		if _, err := mustParsePath(t, path).Set(clone, NewByte("", 0)); err != nil {
+			t.Fatalf("Failed to change %s: %v", path, err)
+		}
+	}
+	if FormatSNBT(original) != `{b:1b,s:2s,i:3,l:4L,f:5f,d:6d,str:"x",ba:[B;1b],ia:[I;2],la:[L;3L],list:[{n:1}],c:{n:2}}` {
+		t.Errorf("Expected the original to be unchanged by edits to the clone, got %s", FormatSNBT(original))
+	}
+}
+
+func TestParseCopyByteArrays(t *testing.T) {
+	data := decoderTestData()
+	tag, err := ParseNBTWithOptions(data, ParseOptions{CopyByteArrays: true})
+	if err != nil {
+		t.Fatalf("Failed to parse NBT: %v", err)
+	}
+	tag.(*TagCompound).Get("bytes").(*TagByteArray).Value[0] = 9
+	if data[len(data)-4] != 1 {
+		t.Errorf("Expected CopyByteArrays to leave the input unchanged")
+	}
+}
@@ -0,0 +1,128 @@
+package nbt
+
+import (
+	"iter"
+	"slices"
+)
+
+// Get returns the child named name, or nil. With duplicate names, the first one wins.
+func (t *TagCompound) Get(name string) NBTTag {
+	if i := t.lookup(name); i >= 0 {
+		return t.Value[i]
+	}
+	return nil
+}
+
+// Has reports whether the compound has a child named name
+func (t *TagCompound) Has(name string) bool {
+	return t.lookup(name) >= 0
+}
+
+// Set stores tag under its name, replacing the child of that name in place,
+// or else adding it at the end, before the TAG_End.
+// It panics if tag is a TAG_End.
+func (t *TagCompound) Set(tag NBTTag) {
+	if tag.Type() == BTagEnd {
+		panic("nbt: cannot Set a TAG_End in a compound")
+	}
+	if i := t.lookup(tag.Name()); i >= 0 {
+		t.Value[i] = tag
+		if t.index != nil && t.index[tag.Name()] != i {
+			// Value was edited directly since the index was built
+			t.Index()
+		}
+		return
+	}
+	i := t.appendChild(tag)
+	if t.index != nil {
+		t.index[tag.Name()] = i
+	}
+}
+
+// Delete removes the child named name and returns it, or nil if there is none
+func (t *TagCompound) Delete(name string) NBTTag {
+	i := t.lookup(name)
+	if i < 0 {
+		return nil
+	}
+	child := t.Value[i]
+	t.Value = slices.Delete(t.Value, i, i+1)
+	if t.index != nil {
+		// the children after it moved
+		t.Index()
+	}
+	return child
+}
+
+// Keys returns the names of the children in order
+func (t *TagCompound) Keys() []string {
+	keys := make([]string, 0, len(t.Value))
+	for name := range t.All() {
+		keys = append(keys, name)
+	}
+	return keys
+}
+
+// All iterates over the names and children in order, leaving out the TAG_End
+func (t *TagCompound) All() iter.Seq2[string, NBTTag] {
+	return func(yield func(string, NBTTag) bool) {
+		for _, child := range t.Value {
+			if child.Type() == BTagEnd {
+				continue
+			}
+			if !yield(child.Name(), child) {
+				return
+			}
+		}
+	}
+}
+
+// Index builds a name index, so lookups in a large compound no longer scan every child.
+// Set and Delete keep the index up to date. After changing Value directly or renaming a child,
+// call Index again, as a name missing from the index is taken to be missing from the compound.
+func (t *TagCompound) Index() {
+	t.index = make(map[string]int, len(t.Value))
+	for i, child := range t.Value {
+		if child.Type() == BTagEnd {
+			continue
+		}
+		if _, ok := t.index[child.Name()]; !ok {
+			t.index[child.Name()] = i
+		}
+	}
+}
+
+// lookup returns the position of the first child named name, or -1.
+// A nil compound has no children, so that typed getters can be chained.
+// It does not change the compound, so that concurrent reads are safe.
+func (t *TagCompound) lookup(name string) int {
+	if t == nil {
+		return -1
+	}
+	if t.index != nil {
+		i, ok := t.index[name]
+		if !ok {
+			return -1
+		}
+		if i < len(t.Value) && t.Value[i].Type() != BTagEnd && t.Value[i].Name() == name {
+			return i
+		}
+		// Value was edited directly since the index was built, which Set, Delete and Index repair
+	}
+	for i, child := range t.Value {
+		if child.Type() != BTagEnd && child.Name() == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// appendChild adds tag to the end of a compound, keeping its TAG_End last, and returns its position
+func (t *TagCompound) appendChild(tag NBTTag) int {
+	if n := len(t.Value); n > 0 && t.Value[n-1].Type() == BTagEnd {
+		t.Value = append(t.Value[:n-1], tag, t.Value[n-1])
+		return n - 1
+	}
+	t.Value = append(t.Value, tag)
+	return len(t.Value) - 1
+}
@@ -0,0 +1,126 @@
+package nbt
+
+import (
+	"bytes"
+	"fmt"
+	"slices"
+	"sync"
+	"testing"
+)
+
+func TestCompoundGetSetDelete(t *testing.T) {
+	root := NewCompound("", NewInt("a", 1), NewInt("b", 2))
+
+	if tag := root.Get("b"); tag == nil || tag.(*TagInt).Value != 2 {
+		t.Errorf("Expected b = 2, got %v", tag)
+	}
+	if root.Get("missing") != nil || root.Has("missing") {
+		t.Errorf("Expected no child named missing")
+	}
+
+	// replacing keeps the position, adding goes before the TAG_End
+	root.Set(NewString("a", "replaced"))
+	root.Set(NewLong("c", 3))
+	if keys := root.Keys(); !slices.Equal(keys, []string{"a", "b", "c"}) {
+		t.Errorf("Expected keys [a b c], got %v", keys)
+	}
+	if last := root.Value[len(root.Value)-1]; last.Type() != BTagEnd {
+		t.Errorf("Expected the TAG_End to stay last, got %s", TagName[last.Type()])
+	}
+
+	if deleted := root.Delete("b"); deleted == nil || deleted.Name() != "b" {
+		t.Errorf("Expected Delete to return b, got %v", deleted)
+	}
+	if root.Delete("b") != nil || root.Has("b") {
+		t.Errorf("Expected b to be gone")
+	}
+	if keys := root.Keys(); !slices.Equal(keys, []string{"a", "c"}) {
+		t.Errorf("Expected keys [a c], got %v", keys)
+	}
+}
+
+func TestCompoundAllKeepsOrder(t *testing.T) {
+	tag, err := ParseNBT(decoderTestData(), false)
+	if err != nil {
+		t.Fatalf("Failed to parse NBT: %v", err)
+	}
+	root := tag.(*TagCompound)
+
+	var names []string
+	for name, child := range root.All() {
+		if child.Name() != name {
+			t.Errorf("Expected the child of %s to be named the same, got %s", name, child.Name())
+		}
+		names = append(names, name)
+	}
+	if !slices.Equal(names, []string{"title", "numbers", "bytes"}) {
+		t.Errorf("Expected [title numbers bytes], got %v", names)
+	}
+
+	// editing through Set leaves the bytes unchanged
+	root.Set(NewString("title", "Stream"))
+	serialized, serializeErr := SerializeNBT(root, false)
+	if serializeErr != nil {
+		t.Fatalf("Failed to serialize NBT: %v", serializeErr)
+	}
+	if !bytes.Equal(serialized, decoderTestData()) {
+		t.Errorf("Serialized mismatch.\nGot:      % x\nExpected: % x", serialized, decoderTestData())
+	}
+}
+
+func TestCompoundIndex(t *testing.T) {
+	root := NewCompound("")
+	for i := range 100 {
+		root.Set(NewInt(fmt.Sprintf("key%d", i), int32(i)))
+	}
+	root.Index()
+
+	if tag := root.Get("key42"); tag == nil || tag.(*TagInt).Value != 42 {
+		t.Errorf("Expected key42 = 42, got %v", tag)
+	}
+	root.Delete("key10")
+	root.Set(NewInt("extra", -1))
+	if tag := root.Get("key99"); tag == nil || tag.(*TagInt).Value != 99 {
+		t.Errorf("Expected key99 = 99 after a delete, got %v", tag)
+	}
+	if tag := root.Get("extra"); tag == nil || tag.(*TagInt).Value != -1 {
+		t.Errorf("Expected extra = -1, got %v", tag)
+	}
+
+	// a direct edit moves key50, which the next lookup notices
+	root.Value = slices.Delete(root.Value, 0, 1)
+	if tag := root.Get("key50"); tag == nil || tag.(*TagInt).Value != 50 {
+		t.Errorf("Expected key50 = 50 after a direct edit, got %v", tag)
+	}
+
+	// Set repairs the index
+	root.Set(NewInt("key50", 500))
+	if i := root.index["key60"]; root.Value[i].Name() != "key60" {
+		t.Errorf("Expected Set to rebuild a stale index")
+	}
+}
+
+func TestCompoundConcurrentGet(t *testing.T) {
+	root := NewCompound("")
+	for i := range 100 {
+		root.Set(NewInt(fmt.Sprintf("key%d", i), int32(i)))
+	}
+	root.Index()
+	root.Value = slices.Delete(root.Value, 0, 1)
+
+	// lookups through a stale index must not write to the compound, run with -race
+	var wg sync.WaitGroup
+	for range 4 {
+		wg.Go(func() {
+			for i := 1; i < 100; i++ {
+				if tag := root.Get(fmt.Sprintf("key%d", i)); tag == nil || tag.(*TagInt).Value != int32(i) {
+					t.Errorf("Expected key%d = %d, got %v", i, i, tag)
+				}
+			}
+		})
+	}
+	wg.Wait()
+	if root.index["key50"] != 50 {
+		t.Errorf("Expected Get to leave the index as it was")
+	}
+}
@@ -0,0 +1,120 @@
+package nbt
+
+import (
+	"encoding/binary"
+	"io"
+)
+
+// Decoder reads NBT tags from a stream, such as the gzip or zlib readers from lib.NewUnzipReader,
+// without loading the whole input into memory first
+type Decoder struct {
+	src          *readerSource
+	parser       parser
+	namelessRoot bool
+
+	storageVersion int32
+	levelHeader    bool
+}
+
+// NewDecoder returns a Decoder reading Java (big-endian) or Bedrock (little-endian) NBT from r
+func NewDecoder(r io.Reader, isBedrock bool) *Decoder {
+	src := newReaderSource(r)
+	return &Decoder{
+		src:    src,
+		parser: newParser(src, ParseOptions{Encoding: encodingFor(isBedrock)}),
+	}
+}
+
+// SetEncoding switches the NBT variant used for the following tags
+func (d *Decoder) SetEncoding(encoding Encoding) {
+	d.parser.encoding = encoding
+}
+
+// SetWarningHandler installs a handler for problems that do not stop parsing.
+// Without one, they are ignored.
+func (d *Decoder) SetWarningHandler(handler WarningHandler) {
+	d.parser.warn = handler
+}
+
+// SetLimits replaces DefaultLimits for the following tags. They apply to each tag separately.
+func (d *Decoder) SetLimits(limits Limits) {
+	d.parser.limits = limits
+}
+
+// SetNamelessRoot reads root tags without names, see ParseOptions.NamelessRoot
+func (d *Decoder) SetNamelessRoot(namelessRoot bool) {
+	d.namelessRoot = namelessRoot
+}
+
+// Decode reads the next root tag from the stream.
+//
+// It returns io.EOF if the stream ends before another tag begins.
+// In the Bedrock encoding, a level.dat header at the start of the stream is detected and skipped, see StorageVersion.
+// Parse failures are reported as a TagParseError carrying the byte offset in the stream.
+// With ParseOptions.Lenient, the partial tag is returned along with a ParseErrors.
+func (d *Decoder) Decode() (NBTTag, error) {
+	if _, err := d.src.r.Peek(1); err != nil {
+		return nil, err
+	}
+	var levelLength int64
+	if d.src.offset() == 0 && d.parser.encoding == EncodingBedrock && !d.namelessRoot {
+		if peek, _ := d.src.r.Peek(levelHeaderSize + 1); looksLikeLevelHeader(peek) {
+			header, _ := d.src.next(levelHeaderSize)
+			d.storageVersion = int32(binary.LittleEndian.Uint32(header[0:4]))
+			d.levelHeader = true
+			levelLength = int64(binary.LittleEndian.Uint32(header[4:levelHeaderSize]))
+		}
+	}
+	start := d.src.offset()
+	d.parser.depth, d.parser.allocated, d.parser.errs = 0, 0, nil
+	tag, err := d.parser.readRoot(d.namelessRoot)
+	if err != nil {
+		if !d.parser.lenient {
+			return nil, err
+		}
+		// the stream is out of sync, so nothing after the partial tag can be read
+		return d.partial(tag, err)
+	}
+	if !d.parser.validRoot(tag) {
+		rootErr := newParseValueError("failed to decode NBT", start, tag.Type(), ErrInvalidRoot)
+		if !d.parser.lenient {
+			return nil, rootErr
+		}
+		d.parser.errs = append(d.parser.errs, rootErr)
+	}
+	if levelLength > 0 && d.src.offset()-start != levelLength {
+		lengthErr := newParseArrayError("level.dat header length does not match the NBT data", 4, tag.Type(), nil)
+		if !d.parser.tolerate(lengthErr) {
+			return nil, lengthErr
+		}
+	}
+	return d.partial(tag, nil)
+}
+
+// partial returns a tag from lenient decoding along with the errors collected for it
+func (d *Decoder) partial(tag NBTTag, err TagParseError) (NBTTag, error) {
+	if err != nil {
+		d.parser.errs = append(d.parser.errs, err)
+	}
+	if len(d.parser.errs) == 0 {
+		return tag, nil
+	}
+	return tag, ParseErrors(d.parser.errs)
+}
+
+// StorageVersion returns the storage version from a Bedrock level.dat header,
+// and whether the stream started with such a header
+func (d *Decoder) StorageVersion() (int32, bool) {
+	return d.storageVersion, d.levelHeader
+}
+
+// More reports whether there is unread data left in the stream
+func (d *Decoder) More() bool {
+	_, err := d.src.r.Peek(1)
+	return err == nil
+}
+
+// InputOffset returns the number of bytes consumed from the stream so far
+func (d *Decoder) InputOffset() int64 {
+	return d.src.offset()
+}
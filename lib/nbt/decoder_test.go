@@ -0,0 +1,116 @@
+package nbt
+
+import (
+	"bytes"
+	"errors"
+	"goNbt/lib"
+	"io"
+	"testing"
+	"testing/iotest"
+)
+
+func decoderTestData() []byte {
+	// TAG_Compound "root" with a TAG_String, a TAG_List of TAG_Int and a TAG_Byte_Array
+	data := []byte{byte(BTagCompound)}
+	data = append(data, lib.UInt16ToBytes(4, true)...)
+	data = append(data, []byte("root")...)
+
+	data = append(data, byte(BTagString))
+	data = append(data, lib.UInt16ToBytes(5, true)...)
+	data = append(data, []byte("title")...)
+	data = append(data, lib.UInt16ToBytes(6, true)...)
+	data = append(data, []byte("Stream")...)
+
+	data = append(data, byte(BTagList))
+	data = append(data, lib.UInt16ToBytes(7, true)...)
+	data = append(data, []byte("numbers")...)
+	data = append(data, byte(BTagInt))
+	data = append(data, lib.Int32ToBytes(2, true)...)
+	data = append(data, lib.Int32ToBytes(7, true)...)
+	data = append(data, lib.Int32ToBytes(8, true)...)
+
+	data = append(data, byte(BTagByteArray))
+	data = append(data, lib.UInt16ToBytes(5, true)...)
+	data = append(data, []byte("bytes")...)
+	data = append(data, lib.Int32ToBytes(3, true)...)
+	data = append(data, 1, 2, 3)
+
+	data = append(data, byte(BTagEnd))
+	return data
+}
+
+func TestDecoderMatchesParseNBT(t *testing.T) {
+	data := decoderTestData()
+	expected, parseErr := ParseNBT(data, false)
+	if parseErr != nil {
+		t.Fatalf("Failed to parse NBT: %v", parseErr)
+	}
+	expectedBytes, _ := SerializeTag(expected, false)
+
+	// one byte at a time exercises every read boundary of the stream
+	decoder := NewDecoder(iotest.OneByteReader(bytes.NewReader(data)), false)
+	tag, err := decoder.Decode()
+	if err != nil {
+		t.Fatalf("Failed to decode NBT: %v", err)
+	}
+	decodedBytes, _ := SerializeTag(tag, false)
+	if !bytes.Equal(decodedBytes, expectedBytes) {
+		t.Errorf("Decoded tree mismatch.\nGot:      % x\nExpected: % x", decodedBytes, expectedBytes)
+	}
+	if decoder.InputOffset() != int64(len(data)) {
+		t.Errorf("Expected input offset %d, got %d", len(data), decoder.InputOffset())
+	}
+	if decoder.More() {
+		t.Errorf("Expected no more data")
+	}
+	if _, err := decoder.Decode(); err != io.EOF {
+		t.Errorf("Expected io.EOF after last tag, got %v", err)
+	}
+}
+
+func TestDecoderGzipStream(t *testing.T) {
+	data := decoderTestData()
+	compressed, err := lib.ZipToGzip(data)
+	if err != nil {
+		t.Fatalf("Failed to gzip data: %v", err)
+	}
+	reader, err := lib.NewUnzipReader(bytes.NewReader(compressed))
+	if err != nil {
+		t.Fatalf("Failed to open gzip stream: %v", err)
+	}
+	defer reader.Close()
+
+	tag, err := NewDecoder(reader, false).Decode()
+	if err != nil {
+		t.Fatalf("Failed to decode gzip stream: %v", err)
+	}
+	compound, ok := tag.(*TagCompound)
+	if !ok {
+		t.Fatalf("Expected *TagCompound, got %T", tag)
+	}
+	byteArray, ok := compound.Value[2].(*TagByteArray)
+	if !ok {
+		t.Fatalf("Third child: expected *TagByteArray, got %T", compound.Value[2])
+	}
+	if !bytes.Equal(byteArray.Value, []byte{1, 2, 3}) {
+		t.Errorf("Expected byte array [1 2 3], got %v", byteArray.Value)
+	}
+}
+
+func TestDecoderReportsOffset(t *testing.T) {
+	data := decoderTestData()
+	// cut the stream in the middle of the list's second element
+	truncated := data[:44]
+
+	_, err := NewDecoder(bytes.NewReader(truncated), false).Decode()
+	if err == nil {
+		t.Fatalf("Expected an error for truncated input")
+	}
+	var parseErr TagParseError
+	if !errors.As(err, &parseErr) {
+		t.Fatalf("Expected a TagParseError, got %T", err)
+	}
+	if parseErr.Offset() != 42 {
+		t.Errorf("Expected failure at offset 42, got %d (%v)", parseErr.Offset(), err)
+	}
+}
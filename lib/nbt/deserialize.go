@@ -1,99 +1,298 @@
 package nbt
 
 import (
-	"encoding/hex"
 	"fmt"
 	"goNbt/lib"
 )
 
-type TagParseError interface {
-	isFatal() bool
-	Error() string
+func ParseNBT(data []byte, isBedrock bool) (NBTTag, TagParseError) {
+	return ParseNBTEncoding(data, encodingFor(isBedrock))
+}
+
+// ParseNBTEncoding parses a single root Compound or List tag in the given encoding.
+func ParseNBTEncoding(data []byte, encoding Encoding) (NBTTag, TagParseError) {
+	return parseRoot(data, ParseOptions{Encoding: encoding})
+}
+
+// ParseNBTNamelessRoot parses a root tag without a name, see ParseOptions.NamelessRoot
+func ParseNBTNamelessRoot(data []byte, encoding Encoding) (NBTTag, TagParseError) {
+	return parseRoot(data, ParseOptions{Encoding: encoding, NamelessRoot: true})
+}
+
+func parseRoot(data []byte, opts ParseOptions) (NBTTag, TagParseError) {
+	tag, errs := readRootData(data, opts)
+	if len(errs) > 0 {
+		return nil, errs[0]
+	}
+	return tag, nil
+}
+
+// readRootData parses data as a root tag. Without Lenient, it stops at the first error.
+// In lenient mode it returns the partial tree, if the root could be started, along with every error found.
+func readRootData(data []byte, opts ParseOptions) (NBTTag, []TagParseError) {
+	src := &sliceSource{data: data}
+	if opts.Encoding == EncodingBedrock && !opts.NamelessRoot && hasLevelHeader(data) {
+		// Bedrock level.dat, the storage version is only kept by ParseBedrockLevelDat
+		src.pos = levelHeaderSize
+	}
+	rootStart := src.pos
+	p := newParser(src, opts)
+	tag, err := p.readRoot(opts.NamelessRoot)
+	if err != nil {
+		// the input is out of sync, so the rest of it cannot be checked
+		return tag, append(p.errs, err)
+	}
+	if remaining := len(data) - src.pos; remaining > 0 && opts.TrailingData != TrailingDataIgnore {
+		message := fmt.Sprintf("%d bytes left after the root tag", remaining)
+		trailingErr := newParseArrayError(message, int64(src.pos), tag.Type(), ErrTrailingData)
+		if opts.TrailingData == TrailingDataWarn {
+			if opts.Warn != nil {
+				opts.Warn(trailingErr)
+			}
+		} else if !p.tolerate(trailingErr) {
+			return nil, []TagParseError{trailingErr}
+		}
+	}
+	if !p.validRoot(tag) {
+		rootErr := newParseValueError("failed to parse NBT", int64(rootStart), tag.Type(), ErrInvalidRoot)
+		if !p.lenient {
+			return nil, []TagParseError{rootErr}
+		}
+		p.errs = append(p.errs, rootErr)
+	}
+	return tag, p.errs
+}
+
+// separateSingleTag parses a single NBT tag (with known length of data) from the given byte slice.
+//
+// It returns the parsed Tag, any remaining unparsed bytes, and an error if parsing fails.
+func separateSingleTag(data []byte, bigEndian bool) (NBTTag, []byte, TagParseError) {
+	src := &sliceSource{data: data}
+	p := newParser(src, ParseOptions{Encoding: encodingFor(!bigEndian)})
+	tag, err := p.readTag()
+	if err != nil {
+		return nil, nil, err
+	}
+	return tag, data[src.pos:], nil
 }
 
-type tagParseValueError struct {
-	message string
+// parser reads tags sequentially from a byteSource,
+// so the same logic serves both in-memory data and streams
+type parser struct {
+	src      byteSource
+	encoding Encoding
+	strings  StringEncoding
+	// path leads from the root to the tag being read, for error reports
+	path []pathSegment
+	// warn receives problems that do not stop parsing, nil to ignore them
+	warn WarningHandler
+
+	limits    Limits
+	depth     int
+	allocated int64
+
+	// lenient keeps going after errors that leave the input in sync, collecting them in errs,
+	// and returns partial containers for the others
+	lenient bool
+	errs    []TagParseError
+
+	scalarRoots bool
 }
-type tagParseArrayError struct {
-	message string
+
+func newParser(src byteSource, opts ParseOptions) parser {
+	limits := opts.Limits
+	if limits == (Limits{}) {
+		limits = DefaultLimits
+	}
+	if s, ok := src.(*sliceSource); ok && opts.CopyByteArrays {
+		s.copyTaken = true
+	}
+	return parser{
+		src:      src,
+		encoding: opts.Encoding,
+		strings:  opts.StringEncoding,
+		warn:     opts.Warn,
+		limits:   limits,
+		lenient:  opts.Lenient,
+
+		scalarRoots: opts.AllowScalarRoot,
+	}
 }
 
-func (e tagParseValueError) Error() string {
-	return e.message
+// validRoot reports whether tag may be a root: a Compound or List, or any tag but TAG_End with AllowScalarRoot
+func (p *parser) validRoot(tag NBTTag) bool {
+	switch tag.Type() {
+	case BTagCompound, BTagList:
+		return true
+	case BTagEnd:
+		return false
+	default:
+		return p.scalarRoots
+	}
 }
-func (e tagParseValueError) isFatal() bool {
+
+// tolerate records a non-fatal error in lenient mode, and reports whether parsing may go on
+func (p *parser) tolerate(err TagParseError) bool {
+	if !p.lenient || err.isFatal() {
+		return false
+	}
+	p.errs = append(p.errs, err)
 	return true
 }
-func (e tagParseArrayError) Error() string {
-	return e.message
+
+// WarningHandler receives problems found while parsing that do not stop it,
+// such as input that is accepted but would not serialize back to the same bytes
+type WarningHandler func(warning TagParseError)
+
+func (p *parser) warning(tagType tagTypeByte, message string) {
+	if p.warn != nil {
+		p.warn(tagParseArrayError{newParseErrorInfo(message, p.src.offset(), formatPath(p.path), tagType, nil)})
+	}
 }
-func (e tagParseArrayError) isFatal() bool {
-	return false
+
+func (p *parser) valueError(tagType tagTypeByte, message string, cause error) TagParseError {
+	return tagParseValueError{newParseErrorInfo(message, p.src.offset(), formatPath(p.path), tagType, cause)}
 }
 
-func newParseValueError(message string) TagParseError {
-	return tagParseValueError{message}
+func (p *parser) arrayError(tagType tagTypeByte, message string, cause error) TagParseError {
+	return tagParseArrayError{newParseErrorInfo(message, p.src.offset(), formatPath(p.path), tagType, cause)}
 }
-func newParseArrayError(message string) TagParseError {
-	return tagParseArrayError{message}
+
+func (p *parser) pushName(name string) {
+	p.path = append(p.path, pathSegment{name: name, index: -1})
 }
 
-func ParseNBT(data []byte, isBedrock bool) (NBTTag, TagParseError) {
-	tag, remaining, err := separateSingleTag(data, 0, !isBedrock)
+func (p *parser) pushIndex(index int) {
+	p.path = append(p.path, pathSegment{index: index})
+}
+
+func (p *parser) popPath() {
+	p.path = p.path[:len(p.path)-1]
+}
+
+// readTag reads a full tag: type ID, name and payload.
+func (p *parser) readTag() (NBTTag, TagParseError) {
+	tagType, name, err := p.readHeader()
 	if err != nil {
 		return nil, err
 	}
-	if len(remaining) > 0 {
-		fmt.Printf("Warning: %d bytes of extra data after parsing NBT tag (data: %s)\n", len(remaining), hex.EncodeToString(remaining))
-		return nil, newParseArrayError("extra data after parsing NBT tag")
+	tag := baseTag{tagType, name}
+	if tagType == BTagEnd {
+		return &TagEnd{baseTag: tag}, nil
+	}
+	p.pushName(name)
+	payload, err := p.readPayload(tag)
+	p.popPath()
+	return payload, err
+}
+
+// readRoot reads the root tag, whose name is omitted in the nameless-root format.
+func (p *parser) readRoot(namelessRoot bool) (NBTTag, TagParseError) {
+	tagType, name, err := p.readRootHeader(namelessRoot)
+	if err != nil {
+		return nil, err
 	}
-	if tag.Type() != BTagCompound && tag.Type() != BTagList {
-		return nil, newParseValueError("root tag is not a Compound or List")
+	tag := baseTag{tagType, name}
+	if tagType == BTagEnd {
+		return &TagEnd{baseTag: tag}, nil
 	}
-	return tag, nil
+	return p.readPayload(tag)
 }
 
-// separateSingleTag parses a single NBT tag (with known length of data) from the given byte slice.
-//
-// It returns the parsed Tag, any remaining unparsed bytes, and an error if parsing fails.
-func separateSingleTag(data []byte, zIndex int, bigEndian bool) (NBTTag, []byte, TagParseError) {
-	tagType := tagTypeByte(data[0])
+func (p *parser) readRootHeader(namelessRoot bool) (tagTypeByte, string, TagParseError) {
+	if !namelessRoot {
+		return p.readHeader()
+	}
+	typeByte, err := p.src.readByte()
+	if err != nil {
+		return 0, "", p.valueError(BTagCompound, "data too short for tag type", err)
+	}
+	return tagTypeByte(typeByte), "", nil
+}
+
+// readHeader reads the type ID and name of a tag. TAG_End has no name.
+func (p *parser) readHeader() (tagTypeByte, string, TagParseError) {
+	typeByte, err := p.src.readByte()
+	if err != nil {
+		return 0, "", p.valueError(BTagCompound, "data too short for tag type", err)
+	}
+	tagType := tagTypeByte(typeByte)
 	if tagType == BTagEnd {
-		tag := baseTag{tagType, "", zIndex}
-		return &TagEnd{baseTag: tag}, data[1:], nil
+		return tagType, "", nil
 	}
-	nameLength, err := lib.BytesToUInt16(data[1:3], bigEndian)
+	nameLength, err := p.readStringLength()
 	if err != nil {
-		return nil, nil, newParseValueError("failed to parse name length")
+		return 0, "", p.valueError(tagType, "failed to parse name length", err)
 	}
-	nameLengthInt := int(nameLength)
-	if len(data) < 3+nameLengthInt {
-		fmt.Printf("Error: Type: %s, Name Length: (full 0-2 bytes %s in hex) or %d\n", hex.EncodeToString([]byte{byte(tagType)}), hex.EncodeToString(data[0:3]), nameLengthInt)
-		return nil, nil, newParseValueError("data too short for tag name")
+	nameBytes, err := p.src.next(nameLength)
+	if err != nil {
+		return 0, "", p.valueError(tagType, "data too short for tag name", err)
 	}
-	name := string(data[3 : 3+nameLengthInt])
-	tag := baseTag{tagType, name, zIndex}
-	payload := data[3+nameLengthInt:]
-	parsed, err := parsePayload(tag, payload, bigEndian)
+	name, err := p.decodeString(tagType, nameBytes)
 	if err != nil {
-		return nil, nil, err.(TagParseError)
+		// the name was read in full, so the input is still in sync
+		decodeErr := p.arrayError(tagType, "failed to decode tag name", err)
+		if !p.tolerate(decodeErr) {
+			return 0, "", decodeErr
+		}
+		name = string(nameBytes)
 	}
-	remaining := data[GetTagFullSize(parsed):]
-	return parsed, remaining, nil
+	return tagType, name, nil
 }
 
-// parsePayload parses the payload of a tag based on its type.
-//
-// payload has a unknown length, and may contains other tags data
-// which would be ignored while parsing
-func parsePayload(tag baseTag, payload []byte, bigEndian bool) (NBTTag, TagParseError) {
-	// check length
-	minPayloadLength, ok := TagPayloadLength[tag.Type()]
-	if !ok {
-		return nil, newParseValueError("unknown tag type")
+// decodeString converts name and TAG_String bytes: Modified UTF-8 for Java, UTF-8 for Bedrock,
+// unless a StringEncoding was chosen.
+func (p *parser) decodeString(tagType tagTypeByte, b []byte) (string, error) {
+	if !p.strings.modifiedUTF8(p.encoding) {
+		return string(b), nil
+	}
+	s, err := decodeMUTF8(b)
+	if err == nil && p.warn != nil && replacedSurrogates(b, s) {
+		p.warning(tagType, "unpaired surrogate replaced with U+FFFD, the string will not re-encode to the same bytes")
+	}
+	if err == nil && p.warn != nil && hasFourByteSequences(b) {
+		p.warning(tagType, "standard UTF-8 4 byte sequence read, the string will re-encode as a surrogate pair")
+	}
+	return s, err
+}
+
+// readListHeader reads the element type and length that start a list payload.
+func (p *parser) readListHeader() (tagTypeByte, int, TagParseError) {
+	typeByte, err := p.src.readByte()
+	if err != nil {
+		return 0, 0, p.valueError(BTagList, "payload too short to parse", err)
+	}
+	listLength, err := p.readLength()
+	if err != nil {
+		return 0, 0, p.valueError(BTagList, "failed to parse list length", err)
+	}
+	listType := tagTypeByte(typeByte)
+	if listLength < 0 {
+		// Minecraft reads these as empty lists too
+		p.warning(BTagList, "negative list length read as an empty list")
+		listLength = 0
 	}
-	if minPayloadLength > 0 && len(payload) < minPayloadLength {
-		return nil, newParseValueError("payload too short to parse")
+	if err := p.checkLength(BTagList, listLength); err != nil {
+		return 0, 0, err
+	}
+	if listType == BTagEnd && listLength > 0 {
+		// TAG_End has no payload, so nothing would bound the number of elements
+		listErr := p.arrayError(BTagList, "list of TAG_End cannot have elements", nil)
+		if !p.tolerate(listErr) {
+			return 0, 0, listErr
+		}
+		listLength = 0
+	}
+	return listType, int(listLength), nil
+}
+
+// readPayload reads the payload of a tag based on its type.
+func (p *parser) readPayload(tag baseTag) (NBTTag, TagParseError) {
+	if _, ok := TagPayloadLength[tag.Type()]; !ok {
+		return nil, p.valueError(tag.Type(), "failed to parse payload", ErrUnknownTagType)
+	}
+	if err := p.charge(tag.Type(), tagOverhead+int64(len(tag.name))); err != nil {
+		return nil, err
 	}
 
 	switch tag.Type() {
@@ -103,171 +302,401 @@ func parsePayload(tag baseTag, payload []byte, bigEndian bool) (NBTTag, TagParse
 		}
 	case BTagByte:
 		{
-			return &TagByte{baseTag: tag, Value: payload[0]}, nil
+			value, err := p.src.readByte()
+			if err != nil {
+				return nil, p.valueError(tag.Type(), "payload too short to parse", err)
+			}
+			return &TagByte{baseTag: tag, Value: value}, nil
 		}
 	case BTagShort:
 		{
-			value, error := lib.BytesToInt16(payload[0:2], bigEndian)
-			if error != nil {
-				return nil, newParseValueError("failed to parse short payload")
+			value, err := p.readInt16()
+			if err != nil {
+				return nil, p.valueError(tag.Type(), "failed to parse short payload", err)
 			}
 			return &TagShort{baseTag: tag, Value: value}, nil
 		}
 	case BTagInt:
 		{
-			value, error := lib.BytesToInt32(payload[0:4], bigEndian)
-			if error != nil {
-				return nil, newParseValueError("failed to parse int payload")
+			value, err := p.readInt32()
+			if err != nil {
+				return nil, p.valueError(tag.Type(), "failed to parse int payload", err)
 			}
 			return &TagInt{baseTag: tag, Value: value}, nil
 		}
 	case BTagLong:
 		{
-			value, error := lib.BytesToInt64(payload[0:8], bigEndian)
-			if error != nil {
-				return nil, newParseValueError("failed to parse long payload")
+			value, err := p.readInt64()
+			if err != nil {
+				return nil, p.valueError(tag.Type(), "failed to parse long payload", err)
 			}
 			return &TagLong{baseTag: tag, Value: value}, nil
 		}
 	case BTagFloat:
 		{
-			floatValue, err := lib.BytesFloat32(payload[0:4], bigEndian)
+			b, err := p.src.next(4)
+			if err != nil {
+				return nil, p.valueError(tag.Type(), "payload too short to parse", err)
+			}
+			floatValue, err := lib.BytesFloat32(b, p.encoding.bigEndian())
 			if err != nil {
-				return nil, newParseValueError("failed to parse float payload")
+				return nil, p.valueError(tag.Type(), "failed to parse float payload", err)
 			}
 			return &TagFloat{baseTag: tag, Value: floatValue}, nil
 		}
 	case BTagDouble:
 		{
-			floatValue, err := lib.BytesFloat64(payload[0:8], bigEndian)
+			b, err := p.src.next(8)
 			if err != nil {
-				return nil, newParseValueError("failed to parse double payload")
+				return nil, p.valueError(tag.Type(), "payload too short to parse", err)
+			}
+			floatValue, err := lib.BytesFloat64(b, p.encoding.bigEndian())
+			if err != nil {
+				return nil, p.valueError(tag.Type(), "failed to parse double payload", err)
 			}
 			return &TagDouble{baseTag: tag, Value: floatValue}, nil
 		}
 	case BTagByteArray:
 		{
-			arrayLengthUint, err := lib.BytesToUInt32(payload[0:4], bigEndian)
+			arrayLength, err := p.readLength()
 			if err != nil {
-				return nil, newParseValueError("failed to parse byte array length")
+				return nil, p.valueError(tag.Type(), "failed to parse byte array length", err)
+			}
+			if err := p.checkLength(tag.Type(), arrayLength); err != nil {
+				if !p.tolerate(err) {
+					return nil, err
+				}
+				arrayLength = 0
+			}
+			if err := p.charge(tag.Type(), int64(arrayLength)); err != nil {
+				return nil, err
 			}
-			arrayLength := int(arrayLengthUint)
-			if len(payload) < 4+arrayLength {
-				return nil, newParseValueError("payload too short for byte array")
+			arrayData, err := p.src.take(int(arrayLength))
+			if err != nil {
+				return nil, p.valueError(tag.Type(), "payload too short for byte array", err)
 			}
-			arrayData := payload[4 : 4+arrayLength]
 			return &TagByteArray{baseTag: tag, Value: arrayData}, nil
 		}
 	case BTagString:
 		{
-			stringLengthUint, err := lib.BytesToUInt16(payload[0:2], bigEndian)
+			stringLength, err := p.readStringLength()
 			if err != nil {
-				return nil, newParseValueError("failed to parse string length")
+				return nil, p.valueError(tag.Type(), "failed to parse string length", err)
 			}
-			stringLength := int(stringLengthUint)
-			if len(payload) < 2+stringLength {
-				return nil, newParseValueError("payload too short for string")
+			if err := p.charge(tag.Type(), int64(stringLength)); err != nil {
+				return nil, err
 			}
-			stringData := string(payload[2 : 2+stringLength])
-			return &TagString{baseTag: tag, Value: stringData}, nil
+			stringData, err := p.src.next(stringLength)
+			if err != nil {
+				return nil, p.valueError(tag.Type(), "payload too short for string", err)
+			}
+			value, err := p.decodeString(tag.Type(), stringData)
+			if err != nil {
+				decodeErr := p.arrayError(tag.Type(), "failed to decode string", err)
+				if !p.tolerate(decodeErr) {
+					return nil, decodeErr
+				}
+				value = string(stringData)
+			}
+			return &TagString{baseTag: tag, Value: value}, nil
 		}
 	case BTagList:
 		{
-			listType := tagTypeByte(payload[0])
-			listLengthUint, err := lib.BytesToUInt32(payload[1:5], bigEndian)
+			listType, listLength, err := p.readListHeader()
 			if err != nil {
-				return nil, newParseValueError("failed to parse list length")
+				return nil, err
 			}
-			listLength := int(listLengthUint)
-			items := make([]NBTTag, 0) // unknown size, known length
-			offset := 5
-			for range listLength {
-				itemTag := baseTag{listType, "", tag.zIndex + 1}
-
-				item, err := parsePayload(itemTag, payload[offset:], bigEndian)
+			if err := p.enter(tag.Type()); err != nil {
+				return nil, err
+			}
+			items := make([]NBTTag, 0, min(listLength, maxPrealloc))
+			for i := range listLength {
+				// no type ID and name for list items
+				itemTag := baseTag{listType, ""}
+				p.pushIndex(i)
+				item, err := p.readPayload(itemTag)
+				p.popPath()
 				if err != nil {
-					return nil, newParseArrayError("error parsing list item")
+					if !p.lenient {
+						return nil, err
+					}
+					// keep the elements read so far
+					if item != nil {
+						items = append(items, item)
+					}
+					p.leave()
+					return &TagList{ElementType: listType, baseTag: tag, Value: items, Truncated: true}, err
 				}
 				items = append(items, item)
-				offset += item.DataLength() // no type ID and name for list items
 			}
+			p.leave()
 			return &TagList{ElementType: listType, baseTag: tag, Value: items}, nil
 		}
 	case BTagCompound:
 		{
-			recvTag, remaining, err := separateSingleTag(payload, tag.zIndex+1, bigEndian)
-			if err != nil {
-				return nil, newParseArrayError("error parsing first compound tag")
+			if err := p.enter(tag.Type()); err != nil {
+				return nil, err
 			}
-			arr := []NBTTag{recvTag}
-			for recvTag.Type() != BTagEnd {
-				recvTag, remaining, err = separateSingleTag(remaining, tag.zIndex+1, bigEndian)
+			arr := []NBTTag{}
+			for {
+				recvTag, err := p.readTag()
 				if err != nil {
-					fmt.Println("Error:", err)
-					return nil, newParseArrayError("error in middle while parsing compound tag")
+					if !p.lenient {
+						return nil, err
+					}
+					// keep the tags read so far, and close the compound so the tree still serializes
+					if recvTag != nil {
+						arr = append(arr, recvTag)
+					}
+					arr = append(arr, &TagEnd{baseTag: baseTag{BTagEnd, ""}})
+					p.leave()
+					return &TagCompound{baseTag: tag, Value: arr, Truncated: true}, err
 				}
 				arr = append(arr, recvTag)
+				if recvTag.Type() == BTagEnd {
+					break
+				}
 			}
-			if recvTag.Type() != BTagEnd && len(remaining) == 0 {
-				return nil, newParseArrayError("compound tag ended without TAG_End")
-			}
+			p.leave()
 			return &TagCompound{baseTag: tag, Value: arr}, nil
 		}
 	case BTagIntArray:
 		{
-			arrSize, err := lib.BytesToInt32(payload[0:4], bigEndian)
+			arrSize, err := p.readLength()
 			if err != nil {
-				return nil, newParseValueError("error parsing array size")
-			}
-			offset := 4
-			arr := make([]int32, arrSize)
-			for i := range arrSize {
-				// parse per 4 bytes
-				if len(payload[offset:]) < 4 {
-					return nil, newParseArrayError("error parsing int array tag")
+				return nil, p.valueError(tag.Type(), "error parsing array size", err)
+			}
+			if err := p.checkLength(tag.Type(), arrSize); err != nil {
+				if !p.tolerate(err) {
+					return nil, err
 				}
-				intValue, err := lib.BytesToInt32(payload[offset:offset+4], bigEndian)
+				arrSize = 0
+			}
+			if err := p.charge(tag.Type(), 4*int64(arrSize)); err != nil {
+				return nil, err
+			}
+			arr := make([]int32, 0, min(int(arrSize), maxPrealloc))
+			for range arrSize {
+				intValue, err := p.readInt32()
 				if err != nil {
-					return nil, newParseArrayError("error parsing int array tag value")
+					return nil, p.arrayError(tag.Type(), "error parsing int array tag", err)
 				}
-				arr[i] = intValue
-				offset += 4
+				arr = append(arr, intValue)
 			}
 			return &TagIntArray{baseTag: tag, Value: arr}, nil
 		}
 	case BTagLongArray:
 		{
-			arrSize, err := lib.BytesToInt32(payload[0:4], bigEndian)
+			arrSize, err := p.readLength()
 			if err != nil {
-				return nil, newParseValueError("error parsing array size")
-			}
-			offset := 4
-			arr := make([]int64, arrSize)
-			for i := range arrSize {
-				// parse per 8 bytes
-				if len(payload[offset:]) < 8 {
-					return nil, newParseArrayError("error parsing long array tag")
+				return nil, p.valueError(tag.Type(), "error parsing array size", err)
+			}
+			if err := p.checkLength(tag.Type(), arrSize); err != nil {
+				if !p.tolerate(err) {
+					return nil, err
 				}
-				longValue, err := lib.BytesToInt64(payload[offset:offset+8], bigEndian)
+				arrSize = 0
+			}
+			if err := p.charge(tag.Type(), 8*int64(arrSize)); err != nil {
+				return nil, err
+			}
+			arr := make([]int64, 0, min(int(arrSize), maxPrealloc))
+			for range arrSize {
+				longValue, err := p.readInt64()
 				if err != nil {
-					return nil, newParseArrayError("error parsing long array tag value")
+					return nil, p.arrayError(tag.Type(), "error parsing long array tag", err)
 				}
-				arr[i] = longValue
-				offset += 8
+				arr = append(arr, longValue)
 			}
 			return &TagLongArray{baseTag: tag, Value: arr}, nil
 		}
 	default:
 		{
 
-			return nil, newParseValueError("unknown tag type")
+			return nil, p.valueError(tag.Type(), "failed to parse payload", ErrUnknownTagType)
+		}
+	}
+}
+
+// skipTag discards a full tag without building it, returning the type it skipped.
+func (p *parser) skipTag() (tagTypeByte, TagParseError) {
+	typeByte, err := p.src.readByte()
+	if err != nil {
+		return 0, p.valueError(BTagCompound, "data too short for tag type", err)
+	}
+	tagType := tagTypeByte(typeByte)
+	if tagType == BTagEnd {
+		return tagType, nil
+	}
+	nameLength, err := p.readStringLength()
+	if err != nil {
+		return 0, p.valueError(tagType, "failed to parse name length", err)
+	}
+	if err := p.src.skip(nameLength); err != nil {
+		return 0, p.valueError(tagType, "data too short for tag name", err)
+	}
+	return tagType, p.skipPayload(tagType)
+}
+
+// skipPayload discards the payload of a tag without building it.
+func (p *parser) skipPayload(tagType tagTypeByte) TagParseError {
+	skip := func(n int) TagParseError {
+		if err := p.src.skip(n); err != nil {
+			return p.valueError(tagType, "payload too short to parse", err)
+		}
+		return nil
+	}
+	if size := p.encoding.fixedPayloadSize(tagType); size >= 0 {
+		return skip(size)
+	}
+	switch tagType {
+	case BTagInt:
+		if _, err := p.readInt32(); err != nil {
+			return p.valueError(tagType, "failed to parse int payload", err)
+		}
+		return nil
+	case BTagLong:
+		if _, err := p.readInt64(); err != nil {
+			return p.valueError(tagType, "failed to parse long payload", err)
+		}
+		return nil
+	case BTagString:
+		stringLength, err := p.readStringLength()
+		if err != nil {
+			return p.valueError(tagType, "failed to parse string length", err)
+		}
+		return skip(stringLength)
+	case BTagByteArray, BTagIntArray, BTagLongArray:
+		arrayLength, err := p.readLength()
+		if err != nil {
+			return p.valueError(tagType, "error parsing array size", err)
+		}
+		if arrayLength < 0 {
+			return p.valueError(tagType, "failed to parse array size", ErrNegativeLength)
+		}
+		elementType := arrayElementType(tagType)
+		if size := p.encoding.fixedPayloadSize(elementType); size >= 0 {
+			return skip(int(arrayLength) * size)
+		}
+		for range arrayLength {
+			if err := p.skipPayload(elementType); err != nil {
+				return err
+			}
+		}
+		return nil
+	case BTagList:
+		listType, listLength, err := p.readListHeader()
+		if err != nil {
+			return err
+		}
+		if size := p.encoding.fixedPayloadSize(listType); size >= 0 {
+			return skip(listLength * size)
+		}
+		if err := p.enter(tagType); err != nil {
+			return err
+		}
+		for range listLength {
+			if err := p.skipPayload(listType); err != nil {
+				return err
+			}
+		}
+		p.leave()
+		return nil
+	case BTagCompound:
+		if err := p.enter(tagType); err != nil {
+			return err
+		}
+		for {
+			skipped, err := p.skipTag()
+			if err != nil {
+				return err
+			}
+			if skipped == BTagEnd {
+				p.leave()
+				return nil
+			}
+		}
+	default:
+		return p.valueError(tagType, "failed to skip payload", ErrUnknownTagType)
+	}
+}
+
+// The primitive readers below return plain errors; callers turn them into TagParseErrors.
+
+func (p *parser) readInt16() (int16, error) {
+	b, err := p.src.next(2)
+	if err != nil {
+		return 0, err
+	}
+	return lib.BytesToInt16(b, p.encoding.bigEndian())
+}
+
+// readInt32 reads a TAG_Int value, which is a zig-zag VarInt in the network encoding
+func (p *parser) readInt32() (int32, error) {
+	if p.encoding == EncodingBedrockNetwork {
+		v, err := p.readUvarint(5)
+		return zigzagDecode32(uint32(v)), err
+	}
+	b, err := p.src.next(4)
+	if err != nil {
+		return 0, err
+	}
+	return lib.BytesToInt32(b, p.encoding.bigEndian())
+}
+
+// readInt64 reads a TAG_Long value, which is a zig-zag VarLong in the network encoding
+func (p *parser) readInt64() (int64, error) {
+	if p.encoding == EncodingBedrockNetwork {
+		v, err := p.readUvarint(10)
+		return zigzagDecode64(v), err
+	}
+	b, err := p.src.next(8)
+	if err != nil {
+		return 0, err
+	}
+	return lib.BytesToInt64(b, p.encoding.bigEndian())
+}
+
+// readLength reads the signed 32 bit length of arrays and lists
+func (p *parser) readLength() (int32, error) {
+	return p.readInt32()
+}
+
+// readStringLength reads the length of names and strings:
+// an unsigned 16 bit integer, or an unsigned VarInt in the network encoding
+func (p *parser) readStringLength() (int, error) {
+	if p.encoding == EncodingBedrockNetwork {
+		v, err := p.readUvarint(5)
+		return int(uint32(v)), err
+	}
+	b, err := p.src.next(2)
+	if err != nil {
+		return 0, err
+	}
+	length, err := lib.BytesToUInt16(b, p.encoding.bigEndian())
+	return int(length), err
+}
+
+// readUvarint reads an unsigned LEB128 VarInt of at most maxBytes bytes
+func (p *parser) readUvarint(maxBytes int) (uint64, error) {
+	var value uint64
+	for i := range maxBytes {
+		b, err := p.src.readByte()
+		if err != nil {
+			return 0, err
+		}
+		value |= uint64(b&0x7F) << (7 * i)
+		if b&0x80 == 0 {
+			return value, nil
 		}
 	}
+	return 0, ErrVarintOverflow
 }
 
+// GetTagFullSize is the size of a named tag in the Java encoding, see NBTTag.DataLength
 func GetTagFullSize(tag NBTTag) int {
 	if tag.Type() == BTagEnd {
 		return 1
 	}
-	return 1 + 2 + len(tag.Name()) + tag.DataLength()
+	return 1 + 2 + mutf8Length(tag.Name()) + tag.DataLength()
 }
@@ -14,7 +14,7 @@ func TestDeserializeTagByte(t *testing.T) {
 	data = append(data, []byte("testByte")...)
 	data = append(data, 42) // Value
 
-	tag, remaining, err := separateSingleTag(data, 0, true)
+	tag, remaining, err := separateSingleTag(data, true)
 	if err != nil {
 		t.Fatalf("Failed to parse TagByte: %v", err)
 	}
@@ -43,7 +43,7 @@ func TestDeserializeTagShort(t *testing.T) {
 	data = append(data, []byte("testShort")...)
 	data = append(data, lib.Int16ToBytes(-1234, true)...) // Value
 
-	tag, remaining, err := separateSingleTag(data, 0, true)
+	tag, remaining, err := separateSingleTag(data, true)
 	if err != nil {
 		t.Fatalf("Failed to parse TagShort: %v", err)
 	}
@@ -72,7 +72,7 @@ func TestDeserializeTagInt(t *testing.T) {
 	data = append(data, []byte("testInt")...)
 	data = append(data, lib.Int32ToBytes(123456, true)...) // Value
 
-	tag, remaining, err := separateSingleTag(data, 0, true)
+	tag, remaining, err := separateSingleTag(data, true)
 	if err != nil {
 		t.Fatalf("Failed to parse TagInt: %v", err)
 	}
@@ -101,7 +101,7 @@ func TestDeserializeTagLong(t *testing.T) {
 	data = append(data, []byte("testLong")...)
 	data = append(data, lib.Int64ToBytes(9876543210, true)...) // Value
 
-	tag, remaining, err := separateSingleTag(data, 0, true)
+	tag, remaining, err := separateSingleTag(data, true)
 	if err != nil {
 		t.Fatalf("Failed to parse TagLong: %v", err)
 	}
@@ -130,7 +130,7 @@ func TestDeserializeTagFloat(t *testing.T) {
 	data = append(data, []byte("testFloat")...)
 	data = append(data, lib.Float32ToBytes(3.14159, true)...) // Value
 
-	tag, remaining, err := separateSingleTag(data, 0, true)
+	tag, remaining, err := separateSingleTag(data, true)
 	if err != nil {
 		t.Fatalf("Failed to parse TagFloat: %v", err)
 	}
@@ -161,7 +161,7 @@ func TestDeserializeTagDouble(t *testing.T) {
 	data = append(data, []byte("testDouble")...)
 	data = append(data, lib.Float64ToBytes(2.718281828459045, true)...) // Value
 
-	tag, remaining, err := separateSingleTag(data, 0, true)
+	tag, remaining, err := separateSingleTag(data, true)
 	if err != nil {
 		t.Fatalf("Failed to parse TagDouble: %v", err)
 	}
@@ -191,7 +191,7 @@ func TestDeserializeTagString(t *testing.T) {
 	data = append(data, lib.UInt16ToBytes(11, true)...) // String length
 	data = append(data, []byte("Hello, NBT!")...)
 
-	tag, remaining, err := separateSingleTag(data, 0, true)
+	tag, remaining, err := separateSingleTag(data, true)
 	if err != nil {
 		t.Fatalf("Failed to parse TagString: %v", err)
 	}
@@ -221,7 +221,7 @@ func TestDeserializeTagByteArray(t *testing.T) {
 	data = append(data, lib.Int32ToBytes(5, true)...) // Array length
 	data = append(data, []byte{1, 2, 3, 4, 5}...)     // Array data
 
-	tag, remaining, err := separateSingleTag(data, 0, true)
+	tag, remaining, err := separateSingleTag(data, true)
 	if err != nil {
 		t.Fatalf("Failed to parse TagByteArray: %v", err)
 	}
@@ -261,7 +261,7 @@ func TestDeserializeTagIntArray(t *testing.T) {
 	data = append(data, lib.Int32ToBytes(400, true)...)
 	data = append(data, lib.Int32ToBytes(500, true)...)
 
-	tag, remaining, err := separateSingleTag(data, 0, true)
+	tag, remaining, err := separateSingleTag(data, true)
 	if err != nil {
 		t.Fatalf("Failed to parse TagIntArray: %v", err)
 	}
@@ -301,7 +301,7 @@ func TestDeserializeTagLongArray(t *testing.T) {
 	data = append(data, lib.Int64ToBytes(4000, true)...)
 	data = append(data, lib.Int64ToBytes(5000, true)...)
 
-	tag, remaining, err := separateSingleTag(data, 0, true)
+	tag, remaining, err := separateSingleTag(data, true)
 	if err != nil {
 		t.Fatalf("Failed to parse TagLongArray: %v", err)
 	}
@@ -340,7 +340,7 @@ func TestDeserializeTagList(t *testing.T) {
 	data = append(data, lib.Int32ToBytes(20, true)...)
 	data = append(data, lib.Int32ToBytes(30, true)...)
 
-	tag, remaining, err := separateSingleTag(data, 0, true)
+	tag, remaining, err := separateSingleTag(data, true)
 	if err != nil {
 		t.Fatalf("Failed to parse TagList: %v", err)
 	}
@@ -401,7 +401,7 @@ func TestDeserializeTagCompound(t *testing.T) {
 	// TAG_End
 	data = append(data, byte(BTagEnd))
 
-	tag, remaining, err := separateSingleTag(data, 0, true)
+	tag, remaining, err := separateSingleTag(data, true)
 	if err != nil {
 		t.Fatalf("Failed to parse TagCompound: %v", err)
 	}
@@ -455,7 +455,7 @@ func TestDeserializeTagEnd(t *testing.T) {
 	// TAG_End has no name and no payload
 	data := []byte{byte(BTagEnd)}
 
-	tag, remaining, err := separateSingleTag(data, 0, true)
+	tag, remaining, err := separateSingleTag(data, true)
 	if err != nil {
 		t.Fatalf("Failed to parse TagEnd: %v", err)
 	}
@@ -522,7 +522,7 @@ func TestDeserializeNestedStructure(t *testing.T) {
 	// End root compound
 	data = append(data, byte(BTagEnd))
 
-	tag, remaining, err := separateSingleTag(data, 0, true)
+	tag, remaining, err := separateSingleTag(data, true)
 	if err != nil {
 		t.Fatalf("Failed to parse nested structure: %v", err)
 	}
@@ -628,3 +628,33 @@ func TestParseNBTRoot(t *testing.T) {
 		t.Fatalf("Second child: expected *TagEnd, got %T", compound.Value[1])
 	}
 }
+
+func TestParseNBTNamelessRoot(t *testing.T) {
+	// Java 1.20.2+ network NBT: the root compound's type ID is followed directly by its payload
+	data := []byte{byte(BTagCompound)}
+	data = append(data, byte(BTagInt))
+	data = append(data, lib.UInt16ToBytes(5, true)...)
+	data = append(data, []byte("value")...)
+	data = append(data, lib.Int32ToBytes(42, true)...)
+	data = append(data, byte(BTagEnd))
+
+	tag, err := ParseNBTNamelessRoot(data, EncodingJava)
+	if err != nil {
+		t.Fatalf("Failed to parse nameless root: %v", err)
+	}
+	compound, ok := tag.(*TagCompound)
+	if !ok {
+		t.Fatalf("Expected *TagCompound, got %T", tag)
+	}
+	if compound.Name() != "" {
+		t.Errorf("Expected empty root name, got '%s'", compound.Name())
+	}
+	if childInt := compound.Value[0].(*TagInt); childInt.Value != 42 {
+		t.Errorf("Child: expected value 42, got %d", childInt.Value)
+	}
+
+	// the same bytes read with a root name are misinterpreted
+	if _, err := ParseNBT(data, false); err == nil {
+		t.Errorf("Expected named root parsing of nameless data to fail")
+	}
+}
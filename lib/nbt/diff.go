@@ -0,0 +1,203 @@
+package nbt
+
+import "slices"
+
+// DiffKind says how a Difference changes the tree
+type DiffKind string
+
+const (
+	DiffAdded   DiffKind = "added"
+	DiffRemoved DiffKind = "removed"
+	DiffChanged DiffKind = "changed"
+	// DiffReordered means a compound holds the same keys in another order,
+	// it is only reported without EqualOptions.IgnoreKeyOrder.
+	// Old and New are lists of the strings of the keys in both compounds, in each one's order.
+	DiffReordered DiffKind = "reordered"
+)
+
+// Difference is one entry of a Diff
+type Difference struct {
+	Kind DiffKind `json:"kind"`
+	// Path locates the entry as an NBT path, see ParsePath.
+	// Removed entries are located in the old tree, all others in the new one.
+	Path string `json:"path"`
+	// Old is nil for added entries, New for removed ones
+	Old NBTTag `json:"old,omitempty"`
+	New NBTTag `json:"new,omitempty"`
+}
+
+// maxAlignCells bounds the work spent aligning list elements, beyond it lists are compared index by index
+const maxAlignCells = 1 << 20
+
+// Diff lists what changes from tree a to tree b, in tree order, with tags compared as by Equal.
+// Compounds are compared key by key. Lists and arrays are aligned on their equal elements,
+// so that an element inserted in the middle shows up as added instead of changing all that follow.
+// The names of the roots are not compared.
+func Diff(a, b NBTTag, opts EqualOptions) []Difference {
+	d := &differ{opts: opts}
+	d.diff(a, b)
+	return d.entries
+}
+
+type differ struct {
+	opts    EqualOptions
+	path    []pathSegment
+	entries []Difference
+}
+
+func (d *differ) add(kind DiffKind, old, updated NBTTag) {
+	d.entries = append(d.entries, Difference{kind, formatPath(d.path), old, updated})
+}
+
+// diff compares a and b at the current path
+func (d *differ) diff(a, b NBTTag) {
+	if a.Type() != b.Type() {
+		if !equalValues(a, b, d.opts) {
+			d.add(DiffChanged, a, b)
+		}
+		return
+	}
+	switch a := a.(type) {
+	case *TagCompound:
+		d.diffCompounds(a, b.(*TagCompound))
+	case *TagList:
+		b := b.(*TagList)
+		if a.ElementType != b.ElementType && len(a.Value) > 0 && len(b.Value) > 0 &&
+			!(d.opts.NumericCrossType && isNumericType(a.ElementType) && isNumericType(b.ElementType)) {
+			d.add(DiffChanged, a, b)
+			return
+		}
+		d.diffElements(a, b)
+	case *TagByteArray, *TagIntArray, *TagLongArray:
+		d.diffElements(a, b)
+	default:
+		if !equalValues(a, b, d.opts) {
+			d.add(DiffChanged, a, b)
+		}
+	}
+}
+
+func (d *differ) diffCompounds(a, b *TagCompound) {
+	var common []string
+	for name, child := range a.All() {
+		other := b.Get(name)
+		d.path = append(d.path, pathSegment{name, -1})
+		if other == nil {
+			d.add(DiffRemoved, child, nil)
+		} else {
+			common = append(common, name)
+			d.diff(child, other)
+		}
+		d.path = d.path[:len(d.path)-1]
+	}
+	var order []string
+	for name, child := range b.All() {
+		if !a.Has(name) {
+			d.path = append(d.path, pathSegment{name, -1})
+			d.add(DiffAdded, nil, child)
+			d.path = d.path[:len(d.path)-1]
+			continue
+		}
+		order = append(order, name)
+	}
+	if !d.opts.IgnoreKeyOrder && !slices.Equal(common, order) {
+		d.add(DiffReordered, keyList(common), keyList(order))
+	}
+}
+
+// keyList returns the keys as a list of strings
+func keyList(keys []string) *TagList {
+	list := NewList("", BTagString)
+	for _, key := range keys {
+		list.Value = append(list.Value, NewString("", key))
+	}
+	return list
+}
+
+// diffElements compares two lists or two arrays
+func (d *differ) diffElements(a, b NBTTag) {
+	n, m := elementCount(a), elementCount(b)
+	equal := func(i, j int) bool {
+		return equalValues(elementAt(a, i), elementAt(b, j), d.opts)
+	}
+	if aValues, ok := integerValues(a); ok && a.Type() != BTagList {
+		// arrays compare without making a tag for each element
+		bValues, _ := integerValues(b)
+		equal = func(i, j int) bool { return aValues[i] == bValues[j] }
+	}
+
+	// the common prefix and suffix need no alignment
+	start := 0
+	for start < n && start < m && equal(start, start) {
+		start++
+	}
+	end := 0
+	for end < n-start && end < m-start && equal(n-1-end, m-1-end) {
+		end++
+	}
+
+	// walk the unequal middle between pairs of aligned elements
+	i, j := start, start
+	for _, pair := range alignElements(start, n-end, start, m-end, equal) {
+		d.diffRun(a, b, i, pair[0], j, pair[1])
+		i, j = pair[0]+1, pair[1]+1
+	}
+	d.diffRun(a, b, i, n-end, j, m-end)
+}
+
+// diffRun reports the unaligned elements a[i:iEnd] against b[j:jEnd]:
+// pairs of them as changed, and the rest as removed or added
+func (d *differ) diffRun(a, b NBTTag, i, iEnd, j, jEnd int) {
+	for ; i < iEnd && j < jEnd; i, j = i+1, j+1 {
+		d.path = append(d.path, pathSegment{"", j})
+		d.diff(elementAt(a, i), elementAt(b, j))
+		d.path = d.path[:len(d.path)-1]
+	}
+	for ; i < iEnd; i++ {
+		d.path = append(d.path, pathSegment{"", i})
+		d.add(DiffRemoved, elementAt(a, i), nil)
+		d.path = d.path[:len(d.path)-1]
+	}
+	for ; j < jEnd; j++ {
+		d.path = append(d.path, pathSegment{"", j})
+		d.add(DiffAdded, nil, elementAt(b, j))
+		d.path = d.path[:len(d.path)-1]
+	}
+}
+
+// alignElements returns the pairs of equal elements of a longest common subsequence
+// of a[iStart:iEnd] and b[jStart:jEnd], or nothing if that would take too long
+func alignElements(iStart, iEnd, jStart, jEnd int, equal func(i, j int) bool) [][2]int {
+	n, m := iEnd-iStart, jEnd-jStart
+	if n == 0 || m == 0 || n*m > maxAlignCells {
+		return nil
+	}
+	// lengths[i][j] is the length of the longest common subsequence of a[iStart+i:] and b[jStart+j:]
+	lengths := make([][]int, n+1)
+	for i := range lengths {
+		lengths[i] = make([]int, m+1)
+	}
+	for i := n - 1; i >= 0; i-- {
+		for j := m - 1; j >= 0; j-- {
+			if equal(iStart+i, jStart+j) {
+				lengths[i][j] = lengths[i+1][j+1] + 1
+			} else {
+				lengths[i][j] = max(lengths[i+1][j], lengths[i][j+1])
+			}
+		}
+	}
+	var pairs [][2]int
+	for i, j := 0, 0; i < n && j < m; {
+		switch {
+		case equal(iStart+i, jStart+j):
+			pairs = append(pairs, [2]int{iStart + i, jStart + j})
+			i++
+			j++
+		case lengths[i+1][j] >= lengths[i][j+1]:
+			i++
+		default:
+			j++
+		}
+	}
+	return pairs
+}
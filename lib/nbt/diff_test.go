@@ -0,0 +1,85 @@
+package nbt
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// formatDiff writes one line per difference, with the values as SNBT
+func formatDiff(differences []Difference) []string {
+	var lines []string
+	for _, difference := range differences {
+		line := fmt.Sprintf("%s %s", difference.Kind, difference.Path)
+		if difference.Old != nil {
+			line += " " + FormatSNBT(difference.Old)
+		}
+		if difference.New != nil {
+			line += " -> " + FormatSNBT(difference.New)
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+func TestDiff(t *testing.T) {
+	a := mustParseSNBT(t, `{Data: {Player: {XpLevel: 30, Name: "Steve", Health: 20f}}, Tags: ["a", "b"]}`)
+	b := mustParseSNBT(t, `{Data: {Player: {XpLevel: 31L, Name: "Steve", Hunger: 5}}, Tags: ["a", "b", "c"]}`)
+
+	expected := []string{
+		"changed Data.Player.XpLevel 30 -> 31L",
+		"removed Data.Player.Health 20f",
+		"added Data.Player.Hunger -> 5",
+		`added Tags[2] -> "c"`,
+	}
+	if lines := formatDiff(Diff(a, b, EqualOptions{})); !slices.Equal(lines, expected) {
+		t.Errorf("Expected\n%s\ngot\n%s", strings.Join(expected, "\n"), strings.Join(lines, "\n"))
+	}
+	if differences := Diff(a, a, EqualOptions{}); len(differences) != 0 {
+		t.Errorf("Expected no differences between a tree and itself, got %v", formatDiff(differences))
+	}
+}
+
+func TestDiffAlignsLists(t *testing.T) {
+	a := mustParseSNBT(t, `{Inventory: [{Slot: 0b}, {Slot: 1b}, {Slot: 2b}], Heights: [I; 1, 2, 3, 4]}`)
+	b := mustParseSNBT(t, `{Inventory: [{Slot: 0b}, {Slot: 5b}, {Slot: 1b}, {Slot: 2b}], Heights: [I; 1, 3, 9]}`)
+
+	expected := []string{
+		"added Inventory[1] -> {Slot:5b}",
+		"removed Heights[1] 2",
+		"changed Heights[2] 4 -> 9",
+	}
+	if lines := formatDiff(Diff(a, b, EqualOptions{})); !slices.Equal(lines, expected) {
+		t.Errorf("Expected\n%s\ngot\n%s", strings.Join(expected, "\n"), strings.Join(lines, "\n"))
+	}
+}
+
+func TestDiffKeyOrder(t *testing.T) {
+	a := mustParseSNBT(t, `{x: 1, y: {z: [I; 1, 2]}, a: 1}`)
+	b := mustParseSNBT(t, `{y: {z: [I; 1, 2]}, x: 1, b: 1}`)
+	expected := []string{"removed a 1", "added b -> 1", `reordered  ["x","y"] -> ["y","x"]`}
+	if lines := formatDiff(Diff(a, b, EqualOptions{})); !slices.Equal(lines, expected) {
+		t.Errorf("Expected the root to be reported as reordered by its shared keys, got %v", lines)
+	}
+	if lines := formatDiff(Diff(a, b, EqualOptions{IgnoreKeyOrder: true})); !slices.Equal(lines, expected[:2]) {
+		t.Errorf("Expected no reordering ignoring key order, got %v", lines)
+	}
+}
+
+func TestDiffJSON(t *testing.T) {
+	a := mustParseSNBT(t, `{"minecraft:name": "old", gone: 1b}`)
+	b := mustParseSNBT(t, `{"minecraft:name": "new"}`)
+	output, err := json.Marshal(Diff(a, b, EqualOptions{}))
+	if err != nil {
+		t.Fatalf("Failed to marshal diff: %v", err)
+	}
+	expected := `[{"kind":"changed","path":"\"minecraft:name\"",` +
+		`"old":{"name":"minecraft:name","type":"string","value":"old"},` +
+		`"new":{"name":"minecraft:name","type":"string","value":"new"}},` +
+		`{"kind":"removed","path":"gone","old":{"name":"gone","type":"byte","value":1}}]`
+	if string(output) != expected {
+		t.Errorf("Expected %s\ngot      %s", expected, output)
+	}
+}
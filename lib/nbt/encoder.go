@@ -0,0 +1,263 @@
+package nbt
+
+import (
+	"bufio"
+	"encoding/binary"
+	"io"
+	"math"
+)
+
+// encodeWriter is satisfied by *bytes.Buffer and *bufio.Writer, which are written to without extra buffering
+type encodeWriter interface {
+	io.Writer
+	io.ByteWriter
+	io.StringWriter
+}
+
+// Encoder writes NBT tags directly to a stream in a single pass
+type Encoder struct {
+	w         encodeWriter
+	buffered  *bufio.Writer // set when the Encoder added its own buffering and must flush it
+	encoding  Encoding
+	strings   StringEncoding
+	scratch   [binary.MaxVarintLen64]byte
+	stringBuf []byte // reused for Modified UTF-8 conversion
+	err       error
+
+	namelessRoot bool
+	compressor   io.WriteCloser // set by NewEncoderWithOptions when compressing
+}
+
+// NewEncoder returns an Encoder writing Java (big-endian) or Bedrock (little-endian) NBT to w
+func NewEncoder(w io.Writer, isBedrock bool) *Encoder {
+	return newEncoder(w, encodingFor(isBedrock))
+}
+
+func newEncoder(w io.Writer, encoding Encoding) *Encoder {
+	e := &Encoder{encoding: encoding}
+	if ew, ok := w.(encodeWriter); ok {
+		e.w = ew
+	} else {
+		e.buffered = bufio.NewWriter(w)
+		e.w = e.buffered
+	}
+	return e
+}
+
+// SetEncoding switches the NBT variant used for the following tags
+func (e *Encoder) SetEncoding(encoding Encoding) {
+	e.encoding = encoding
+}
+
+// SetNamelessRoot writes root tags without names, see ParseOptions.NamelessRoot
+func (e *Encoder) SetNamelessRoot(namelessRoot bool) {
+	e.namelessRoot = namelessRoot
+}
+
+// Encode writes tag, including its type ID and name, and flushes any buffering added by the Encoder
+func (e *Encoder) Encode(tag NBTTag) error {
+	if err := e.writeRoot(tag); err != nil {
+		return err
+	}
+	if e.buffered != nil {
+		e.err = e.buffered.Flush()
+	}
+	return e.err
+}
+
+// Close finishes the compressed stream selected by SerializeOptions.Compression.
+// It does not close the underlying writer.
+func (e *Encoder) Close() error {
+	if e.buffered != nil && e.err == nil {
+		e.err = e.buffered.Flush()
+	}
+	if e.compressor != nil {
+		if err := e.compressor.Close(); err != nil && e.err == nil {
+			e.err = err
+		}
+		e.compressor = nil
+	}
+	return e.err
+}
+
+func (e *Encoder) writeRoot(tag NBTTag) error {
+	if !e.namelessRoot {
+		return e.writeTag(tag, false)
+	}
+	e.writeByte(byte(tag.Type()))
+	return e.writeTag(tag, true)
+}
+
+// writeTag writes tag to the stream.
+//
+// skipHeader omits the type ID and name, as used for list elements.
+func (e *Encoder) writeTag(tag NBTTag, skipHeader bool) error {
+	if !skipHeader {
+		e.writeByte(byte(tag.Type()))
+		if tag.Type() == BTagEnd {
+			// TAG_End has no name or payload
+			return e.err
+		}
+		e.writeNBTString(tag.Name())
+	}
+
+	switch t := tag.(type) {
+	case *TagByte:
+		e.writeByte(t.Value)
+	case *TagShort:
+		e.writeUint16(uint16(t.Value))
+	case *TagInt:
+		e.writeInt32(t.Value)
+	case *TagLong:
+		e.writeInt64(t.Value)
+	case *TagFloat:
+		e.writeUint32(math.Float32bits(t.Value))
+	case *TagDouble:
+		e.writeUint64(math.Float64bits(t.Value))
+	case *TagByteArray:
+		e.writeLength(len(t.Value))
+		e.write(t.Value)
+	case *TagString:
+		e.writeNBTString(t.Value)
+	case *TagIntArray:
+		e.writeLength(len(t.Value))
+		for _, val := range t.Value {
+			e.writeInt32(val)
+		}
+	case *TagLongArray:
+		e.writeLength(len(t.Value))
+		for _, val := range t.Value {
+			e.writeInt64(val)
+		}
+	case *TagEnd:
+	case *TagList:
+		e.writeByte(byte(t.ElementType))
+		e.writeLength(len(t.Value))
+		for _, element := range t.Value {
+			if err := e.writeTag(element, true); err != nil {
+				return err
+			}
+		}
+	case *TagCompound:
+		for _, childTag := range t.Value {
+			if err := e.writeTag(childTag, false); err != nil {
+				return err
+			}
+		}
+	default:
+		// For unsupported tag types
+		return createSerializeError("serialization for this tag type not implemented")
+	}
+	return e.err
+}
+
+// the write helpers below keep the first error and turn later writes into no-ops
+
+func (e *Encoder) write(b []byte) {
+	if e.err == nil {
+		_, e.err = e.w.Write(b)
+	}
+}
+
+func (e *Encoder) writeString(s string) {
+	if e.err == nil {
+		_, e.err = e.w.WriteString(s)
+	}
+}
+
+func (e *Encoder) writeByte(b byte) {
+	if e.err == nil {
+		e.err = e.w.WriteByte(b)
+	}
+}
+
+// writeNBTString writes the length and the string, in Modified UTF-8 for Java and UTF-8 for Bedrock
+func (e *Encoder) writeNBTString(s string) {
+	if e.err != nil {
+		return
+	}
+	if !e.strings.modifiedUTF8(e.encoding) || isPlainASCII(s) {
+		e.writeStringLength(len(s))
+		e.writeString(s)
+		return
+	}
+	e.stringBuf = appendMUTF8(e.stringBuf[:0], s)
+	e.writeStringLength(len(e.stringBuf))
+	e.write(e.stringBuf)
+}
+
+// writeStringLength writes an unsigned 16 bit length, or an unsigned VarInt in the network encoding
+func (e *Encoder) writeStringLength(length int) {
+	if e.encoding == EncodingBedrockNetwork {
+		if length > math.MaxInt32 {
+			e.err = createSerializeError("string too long to serialize")
+			return
+		}
+		e.writeUvarint(uint64(length))
+		return
+	}
+	if length > math.MaxUint16 {
+		e.err = createSerializeError("string too long to serialize")
+		return
+	}
+	e.writeUint16(uint16(length))
+}
+
+// writeLength writes the signed 32 bit length of arrays and lists
+func (e *Encoder) writeLength(length int) {
+	if length > math.MaxInt32 {
+		e.err = createSerializeError("array too long to serialize")
+		return
+	}
+	e.writeInt32(int32(length))
+}
+
+// writeInt32 writes a TAG_Int value, which is a zig-zag VarInt in the network encoding
+func (e *Encoder) writeInt32(v int32) {
+	if e.encoding == EncodingBedrockNetwork {
+		e.writeUvarint(uint64(zigzagEncode32(v)))
+		return
+	}
+	e.writeUint32(uint32(v))
+}
+
+// writeInt64 writes a TAG_Long value, which is a zig-zag VarLong in the network encoding
+func (e *Encoder) writeInt64(v int64) {
+	if e.encoding == EncodingBedrockNetwork {
+		e.writeUvarint(zigzagEncode64(v))
+		return
+	}
+	e.writeUint64(uint64(v))
+}
+
+func (e *Encoder) writeUvarint(v uint64) {
+	n := binary.PutUvarint(e.scratch[:], v)
+	e.write(e.scratch[:n])
+}
+
+func (e *Encoder) writeUint16(v uint16) {
+	if e.encoding.bigEndian() {
+		binary.BigEndian.PutUint16(e.scratch[:2], v)
+	} else {
+		binary.LittleEndian.PutUint16(e.scratch[:2], v)
+	}
+	e.write(e.scratch[:2])
+}
+
+func (e *Encoder) writeUint32(v uint32) {
+	if e.encoding.bigEndian() {
+		binary.BigEndian.PutUint32(e.scratch[:4], v)
+	} else {
+		binary.LittleEndian.PutUint32(e.scratch[:4], v)
+	}
+	e.write(e.scratch[:4])
+}
+
+func (e *Encoder) writeUint64(v uint64) {
+	if e.encoding.bigEndian() {
+		binary.BigEndian.PutUint64(e.scratch[:8], v)
+	} else {
+		binary.LittleEndian.PutUint64(e.scratch[:8], v)
+	}
+	e.write(e.scratch[:8])
+}
@@ -0,0 +1,106 @@
+package nbt
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"io"
+	"testing"
+)
+
+type failingWriter struct {
+	remaining int
+}
+
+func (w *failingWriter) Write(p []byte) (int, error) {
+	if len(p) > w.remaining {
+		n := w.remaining
+		w.remaining = 0
+		return n, errors.New("disk full")
+	}
+	w.remaining -= len(p)
+	return len(p), nil
+}
+
+func TestEncoderMatchesSerializeTag(t *testing.T) {
+	tag, parseErr := ParseNBT(decoderTestData(), false)
+	if parseErr != nil {
+		t.Fatalf("Failed to parse NBT: %v", parseErr)
+	}
+	expected, err := SerializeTag(tag, false)
+	if err != nil {
+		t.Fatalf("Failed to serialize: %v", err)
+	}
+
+	var compressed bytes.Buffer
+	zw := gzip.NewWriter(&compressed)
+	if err := NewEncoder(zw, false).Encode(tag); err != nil {
+		t.Fatalf("Failed to encode: %v", err)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatalf("Failed to close gzip writer: %v", err)
+	}
+
+	zr, err := gzip.NewReader(&compressed)
+	if err != nil {
+		t.Fatalf("Failed to open gzip stream: %v", err)
+	}
+	encoded, err := io.ReadAll(zr)
+	if err != nil {
+		t.Fatalf("Failed to read gzip stream: %v", err)
+	}
+	if !bytes.Equal(encoded, expected) {
+		t.Errorf("Encoded data mismatch.\nGot:      % x\nExpected: % x", encoded, expected)
+	}
+}
+
+func TestEncoderListOfCompounds(t *testing.T) {
+	tag := &TagList{
+		baseTag:     baseTag{tagType: BTagList, name: "items"},
+		ElementType: BTagCompound,
+		Value: []NBTTag{
+			&TagCompound{
+				baseTag: baseTag{tagType: BTagCompound},
+				Value: []NBTTag{
+					&TagByte{baseTag: baseTag{tagType: BTagByte, name: "Slot"}, Value: 3},
+					&TagEnd{baseTag: baseTag{tagType: BTagEnd}},
+				},
+			},
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := NewEncoder(&buf, false).Encode(tag); err != nil {
+		t.Fatalf("Failed to encode: %v", err)
+	}
+
+	// list elements carry no type ID or name, only the compound payload
+	expected := []byte{
+		byte(BTagList), 0x00, 0x05, 'i', 't', 'e', 'm', 's',
+		byte(BTagCompound), 0x00, 0x00, 0x00, 0x01,
+		byte(BTagByte), 0x00, 0x04, 'S', 'l', 'o', 't', 3,
+		byte(BTagEnd),
+	}
+	if !bytes.Equal(buf.Bytes(), expected) {
+		t.Errorf("Encoded data mismatch.\nGot:      % x\nExpected: % x", buf.Bytes(), expected)
+	}
+
+	decoded, _, err := separateSingleTag(buf.Bytes(), true)
+	if err != nil {
+		t.Fatalf("Failed to parse encoded list: %v", err)
+	}
+	if len(decoded.(*TagList).Value) != 1 {
+		t.Errorf("Expected 1 list element, got %d", len(decoded.(*TagList).Value))
+	}
+}
+
+func TestEncoderWriteError(t *testing.T) {
+	tag, parseErr := ParseNBT(decoderTestData(), false)
+	if parseErr != nil {
+		t.Fatalf("Failed to parse NBT: %v", parseErr)
+	}
+	err := NewEncoder(&failingWriter{remaining: 10}, false).Encode(tag)
+	if err == nil || err.Error() != "disk full" {
+		t.Errorf("Expected the writer's error, got %v", err)
+	}
+}
@@ -0,0 +1,76 @@
+package nbt
+
+// Encoding selects one of the binary NBT variants
+type Encoding int
+
+const (
+	// Java Edition: big-endian numbers, Modified UTF-8 strings
+	EncodingJava Encoding = iota
+	// Bedrock Edition files: little-endian numbers, UTF-8 strings
+	EncodingBedrock
+	// Bedrock network protocol: little-endian, with TAG_Int, TAG_Long and all lengths written
+	// as zig-zag VarInts/VarLongs, except string and name lengths which are unsigned VarInts
+	EncodingBedrockNetwork
+)
+
+func encodingFor(isBedrock bool) Encoding {
+	if isBedrock {
+		return EncodingBedrock
+	}
+	return EncodingJava
+}
+
+func (e Encoding) bigEndian() bool {
+	return e == EncodingJava
+}
+
+func (e Encoding) String() string {
+	switch e {
+	case EncodingJava:
+		return "java"
+	case EncodingBedrock:
+		return "bedrock"
+	case EncodingBedrockNetwork:
+		return "bedrock-network"
+	default:
+		return "unknown"
+	}
+}
+
+// fixedPayloadSize returns the payload size of tagType when it does not depend on the data, or -1
+func (e Encoding) fixedPayloadSize(tagType tagTypeByte) int {
+	if e == EncodingBedrockNetwork && (tagType == BTagInt || tagType == BTagLong) {
+		return -1
+	}
+	size, ok := TagPayloadLength[tagType]
+	if !ok || size < 0 {
+		return -1
+	}
+	return size
+}
+
+// zig-zag encoding maps signed integers to unsigned ones so small magnitudes stay short as VarInts
+
+func zigzagEncode32(v int32) uint32 { return uint32(v<<1) ^ uint32(v>>31) }
+func zigzagDecode32(v uint32) int32 { return int32(v>>1) ^ -int32(v&1) }
+func zigzagEncode64(v int64) uint64 { return uint64(v<<1) ^ uint64(v>>63) }
+func zigzagDecode64(v uint64) int64 { return int64(v>>1) ^ -int64(v&1) }
+
+// StringEncoding selects how tag names and TAG_String payloads are stored
+type StringEncoding int
+
+const (
+	// Modified UTF-8 for Java Edition, standard UTF-8 for Bedrock Edition
+	StringEncodingDefault StringEncoding = iota
+	// Java's Modified UTF-8, whatever the Encoding
+	StringEncodingMUTF8
+	// Standard UTF-8, whatever the Encoding
+	StringEncodingUTF8
+)
+
+func (s StringEncoding) modifiedUTF8(encoding Encoding) bool {
+	if s == StringEncodingDefault {
+		return encoding == EncodingJava
+	}
+	return s == StringEncodingMUTF8
+}
@@ -0,0 +1,94 @@
+package nbt
+
+import (
+	"bytes"
+	"testing"
+)
+
+func networkTestData() []byte {
+	return []byte{
+		byte(BTagCompound), 0x00, // root, empty name (unsigned VarInt length)
+		byte(BTagInt), 0x01, 'a', 0x01, // -1 zig-zags to 1
+		byte(BTagLong), 0x01, 'b', 0xD8, 0x04, // 300 zig-zags to 600
+		byte(BTagShort), 0x01, 'c', 0x34, 0x12, // shorts stay fixed width little-endian
+		byte(BTagString), 0x01, 's', 0x02, 'h', 'i', // string length is an unsigned VarInt
+		byte(BTagIntArray), 0x01, 'i', 0x04, 0x02, 0x03, // length 2 zig-zags to 4, values 1 and -2
+		byte(BTagList), 0x01, 'l', byte(BTagFloat), 0x02, 0x00, 0x00, 0x80, 0x3F, // one float 1.0
+		byte(BTagEnd),
+	}
+}
+
+func TestParseBedrockNetworkEncoding(t *testing.T) {
+	tag, err := ParseNBTEncoding(networkTestData(), EncodingBedrockNetwork)
+	if err != nil {
+		t.Fatalf("Failed to parse network NBT: %v", err)
+	}
+	root := tag.(*TagCompound)
+	if v := root.Value[0].(*TagInt).Value; v != -1 {
+		t.Errorf("Expected int -1, got %d", v)
+	}
+	if v := root.Value[1].(*TagLong).Value; v != 300 {
+		t.Errorf("Expected long 300, got %d", v)
+	}
+	if v := root.Value[2].(*TagShort).Value; v != 0x1234 {
+		t.Errorf("Expected short 0x1234, got %#x", v)
+	}
+	if v := root.Value[3].(*TagString).Value; v != "hi" {
+		t.Errorf("Expected string 'hi', got %q", v)
+	}
+	if v := root.Value[4].(*TagIntArray).Value; len(v) != 2 || v[0] != 1 || v[1] != -2 {
+		t.Errorf("Expected int array [1 -2], got %v", v)
+	}
+	if v := root.Value[5].(*TagList).Value; len(v) != 1 || v[0].(*TagFloat).Value != 1 {
+		t.Errorf("Expected list [1.0], got %v", v)
+	}
+}
+
+func TestSerializeBedrockNetworkEncoding(t *testing.T) {
+	data := networkTestData()
+	tag, parseErr := ParseNBTEncoding(data, EncodingBedrockNetwork)
+	if parseErr != nil {
+		t.Fatalf("Failed to parse network NBT: %v", parseErr)
+	}
+	serialized, err := SerializeNBTEncoding(tag, EncodingBedrockNetwork)
+	if err != nil {
+		t.Fatalf("Failed to serialize network NBT: %v", err)
+	}
+	if !bytes.Equal(serialized, data) {
+		t.Errorf("Network round trip mismatch.\nGot:      % x\nExpected: % x", serialized, data)
+	}
+
+	// the same tree in the file encoding is different, but converts back losslessly
+	fileBytes, err := SerializeNBTEncoding(tag, EncodingBedrock)
+	if err != nil {
+		t.Fatalf("Failed to serialize Bedrock NBT: %v", err)
+	}
+	fileTag, parseErr := ParseNBTEncoding(fileBytes, EncodingBedrock)
+	if parseErr != nil {
+		t.Fatalf("Failed to parse Bedrock NBT: %v", parseErr)
+	}
+	converted, _ := SerializeNBTEncoding(fileTag, EncodingBedrockNetwork)
+	if !bytes.Equal(converted, data) {
+		t.Errorf("Conversion mismatch.\nGot:      % x\nExpected: % x", converted, data)
+	}
+}
+
+func TestDecoderAndPullParserNetworkEncoding(t *testing.T) {
+	decoder := NewDecoder(bytes.NewReader(networkTestData()), true)
+	decoder.SetEncoding(EncodingBedrockNetwork)
+	if _, err := decoder.Decode(); err != nil {
+		t.Fatalf("Failed to decode network NBT: %v", err)
+	}
+
+	p := NewPullParser(networkTestData(), true)
+	p.SetEncoding(EncodingBedrockNetwork)
+	if _, err := p.Next(); err != nil {
+		t.Fatalf("Failed to read root: %v", err)
+	}
+	if err := p.Skip(); err != nil {
+		t.Fatalf("Failed to skip root: %v", err)
+	}
+	if p.InputOffset() != int64(len(networkTestData())) {
+		t.Errorf("Expected to skip to offset %d, got %d", len(networkTestData()), p.InputOffset())
+	}
+}
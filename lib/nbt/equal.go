@@ -0,0 +1,145 @@
+package nbt
+
+import (
+	"math"
+	"slices"
+)
+
+// EqualOptions relaxes how Equal compares trees. The zero value compares strictly.
+type EqualOptions struct {
+	// IgnoreKeyOrder compares compounds as maps, so the same children in another order are equal
+	IgnoreKeyOrder bool
+	// NaNEqual makes a NaN float or double equal to another NaN
+	NaNEqual bool
+	// NumericCrossType compares numbers by value whatever their type, so 1b equals 1 and 1.0d.
+	// Byte, int and long arrays then compare by their elements too, as do lists of different number types.
+	NumericCrossType bool
+}
+
+// Equal reports whether two trees hold the same data: types, names and values.
+// The names of list elements, which are never serialized, and the Truncated flags are not compared.
+func Equal(a, b NBTTag, opts EqualOptions) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.Name() == b.Name() && equalValues(a, b, opts)
+}
+
+// The Equal methods compare strictly, as Equal with zero EqualOptions.
+// Their form lets github.com/google/go-cmp compare trees with cmp.Equal and cmp.Diff.
+
+func (t *TagByte) Equal(other NBTTag) bool      { return Equal(t, other, EqualOptions{}) }
+func (t *TagShort) Equal(other NBTTag) bool     { return Equal(t, other, EqualOptions{}) }
+func (t *TagInt) Equal(other NBTTag) bool       { return Equal(t, other, EqualOptions{}) }
+func (t *TagLong) Equal(other NBTTag) bool      { return Equal(t, other, EqualOptions{}) }
+func (t *TagFloat) Equal(other NBTTag) bool     { return Equal(t, other, EqualOptions{}) }
+func (t *TagDouble) Equal(other NBTTag) bool    { return Equal(t, other, EqualOptions{}) }
+func (t *TagString) Equal(other NBTTag) bool    { return Equal(t, other, EqualOptions{}) }
+func (t *TagByteArray) Equal(other NBTTag) bool { return Equal(t, other, EqualOptions{}) }
+func (t *TagIntArray) Equal(other NBTTag) bool  { return Equal(t, other, EqualOptions{}) }
+func (t *TagLongArray) Equal(other NBTTag) bool { return Equal(t, other, EqualOptions{}) }
+func (t *TagList) Equal(other NBTTag) bool      { return Equal(t, other, EqualOptions{}) }
+func (t *TagCompound) Equal(other NBTTag) bool  { return Equal(t, other, EqualOptions{}) }
+func (t *TagEnd) Equal(other NBTTag) bool       { return Equal(t, other, EqualOptions{}) }
+
+// equalValues compares two tags without their names
+func equalValues(a, b NBTTag, opts EqualOptions) bool {
+	if a.Type() != b.Type() {
+		if !opts.NumericCrossType {
+			return false
+		}
+		if isNumericType(a.Type()) && isNumericType(b.Type()) {
+			return equalNumbers(a, b, opts)
+		}
+		if arrayElementType(a.Type()) != BTagEnd && arrayElementType(b.Type()) != BTagEnd {
+			aValues, _ := integerValues(a)
+			bValues, _ := integerValues(b)
+			return slices.Equal(aValues, bValues)
+		}
+		return false
+	}
+
+	switch a := a.(type) {
+	case *TagByte, *TagShort, *TagInt, *TagLong, *TagFloat, *TagDouble:
+		return equalNumbers(a, b, opts)
+	case *TagString:
+		return a.Value == b.(*TagString).Value
+	case *TagByteArray:
+		return slices.Equal(a.Value, b.(*TagByteArray).Value)
+	case *TagIntArray:
+		return slices.Equal(a.Value, b.(*TagIntArray).Value)
+	case *TagLongArray:
+		return slices.Equal(a.Value, b.(*TagLongArray).Value)
+	case *TagList:
+		return equalLists(a, b.(*TagList), opts)
+	case *TagCompound:
+		return equalCompounds(a, b.(*TagCompound), opts)
+	case *TagEnd:
+		return true
+	}
+	return false
+}
+
+func isNumericType(tagType tagTypeByte) bool {
+	return tagType >= BTagByte && tagType <= BTagDouble
+}
+
+func equalNumbers(a, b NBTTag, opts EqualOptions) bool {
+	aInt, aFloat, aIsFloat, _ := numericValue(a)
+	bInt, bFloat, bIsFloat, _ := numericValue(b)
+	if !aIsFloat && !bIsFloat {
+		return aInt == bInt
+	}
+	if !aIsFloat {
+		aFloat = float64(aInt)
+	}
+	if !bIsFloat {
+		bFloat = float64(bInt)
+	}
+	if opts.NaNEqual && math.IsNaN(aFloat) && math.IsNaN(bFloat) {
+		return true
+	}
+	return aFloat == bFloat
+}
+
+func equalLists(a, b *TagList, opts EqualOptions) bool {
+	if len(a.Value) != len(b.Value) {
+		return false
+	}
+	if a.ElementType != b.ElementType &&
+		!(opts.NumericCrossType && isNumericType(a.ElementType) && isNumericType(b.ElementType)) {
+		return false
+	}
+	for i := range a.Value {
+		if !equalValues(a.Value[i], b.Value[i], opts) {
+			return false
+		}
+	}
+	return true
+}
+
+func equalCompounds(a, b *TagCompound, opts EqualOptions) bool {
+	aChildren, bChildren := children(a), children(b)
+	if len(aChildren) != len(bChildren) {
+		return false
+	}
+	for i, child := range aChildren {
+		other := bChildren[i]
+		if opts.IgnoreKeyOrder {
+			other = b.Get(child.Name())
+		}
+		if other == nil || child.Name() != other.Name() || !equalValues(child, other, opts) {
+			return false
+		}
+	}
+	return true
+}
+
+// children returns the children of a compound without the TAG_End
+func children(t *TagCompound) []NBTTag {
+	result := make([]NBTTag, 0, len(t.Value))
+	for _, child := range t.All() {
+		result = append(result, child)
+	}
+	return result
+}
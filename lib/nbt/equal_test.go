@@ -0,0 +1,79 @@
+package nbt
+
+import (
+	"math"
+	"testing"
+)
+
+func TestEqualParsedTrees(t *testing.T) {
+	a, err := ParseNBT(decoderTestData(), false)
+	if err != nil {
+		t.Fatalf("Failed to parse NBT: %v", err)
+	}
+	b, _ := ParseNBT(decoderTestData(), false)
+	if !Equal(a, b, EqualOptions{}) {
+		t.Errorf("Expected two parses of the same data to be equal")
+	}
+
+	b.(*TagCompound).Get("numbers").(*TagList).Value[1].(*TagInt).Value = 9
+	if Equal(a, b, EqualOptions{}) {
+		t.Errorf("Expected a changed list element to make the trees differ")
+	}
+
+	b, _ = ParseNBT(decoderTestData(), false)
+	b.SetName("renamed")
+	if Equal(a, b, EqualOptions{}) {
+		t.Errorf("Expected a renamed root to make the trees differ")
+	}
+}
+
+func TestEqualKeyOrder(t *testing.T) {
+	a := mustParseSNBT(t, `{x: 1, y: {p: "a", q: "b"}}`)
+	b := mustParseSNBT(t, `{y: {q: "b", p: "a"}, x: 1}`)
+	if Equal(a, b, EqualOptions{}) {
+		t.Errorf("Expected key order to matter by default")
+	}
+	if !Equal(a, b, EqualOptions{IgnoreKeyOrder: true}) {
+		t.Errorf("Expected the compounds to be equal ignoring key order")
+	}
+	c := mustParseSNBT(t, `{y: {q: "b", p: "a"}, z: 1}`)
+	if Equal(a, c, EqualOptions{IgnoreKeyOrder: true}) {
+		t.Errorf("Expected different keys to differ")
+	}
+}
+
+func TestEqualNumbers(t *testing.T) {
+	nan := NewDouble("", math.NaN())
+	if Equal(nan, NewDouble("", math.NaN()), EqualOptions{}) {
+		t.Errorf("Expected NaN to differ from NaN by default")
+	}
+	if !Equal(nan, NewDouble("", math.NaN()), EqualOptions{NaNEqual: true}) {
+		t.Errorf("Expected NaN to equal NaN with NaNEqual")
+	}
+
+	a := mustParseSNBT(t, `{n: 1b, list: [1, 2], ints: [I; 1, -1], f: 0.5f}`)
+	b := mustParseSNBT(t, `{n: 1.0d, list: [1L, 2L], ints: [B; 1b, -1b], f: 0.5d}`)
+	if Equal(a, b, EqualOptions{}) {
+		t.Errorf("Expected different number types to differ by default")
+	}
+	if !Equal(a, b, EqualOptions{NumericCrossType: true}) {
+		t.Errorf("Expected the numbers to be equal across types")
+	}
+	if Equal(NewInt("", 1), NewString("", "1"), EqualOptions{NumericCrossType: true}) {
+		t.Errorf("Expected a number and a string to differ")
+	}
+}
+
+func TestEqualMethod(t *testing.T) {
+	// the method form used by go-cmp
+	var tag interface{ Equal(NBTTag) bool } = mustParseSNBT(t, `{a: [I; 1]}`).(*TagCompound)
+	if !tag.Equal(mustParseSNBT(t, `{a: [I; 1]}`)) {
+		t.Errorf("Expected Equal to report equal trees")
+	}
+	if tag.Equal(mustParseSNBT(t, `{a: [I; 2]}`)) {
+		t.Errorf("Expected Equal to report different trees")
+	}
+	if NewInt("", 1).Equal(nil) {
+		t.Errorf("Expected a tag to differ from nil")
+	}
+}
@@ -0,0 +1,173 @@
+package nbt
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// Causes wrapped by TagParseError, for use with errors.Is.
+// Truncated input is reported as io.ErrUnexpectedEOF, and read errors from a stream are wrapped unchanged.
+var (
+	ErrUnknownTagType = errors.New("unknown tag type")
+	ErrInvalidMUTF8   = errors.New("invalid modified UTF-8")
+	ErrVarintOverflow = errors.New("varint is too long")
+	ErrNegativeLength = errors.New("negative length")
+	ErrTrailingData   = errors.New("extra data after root tag")
+	ErrInvalidRoot    = errors.New("invalid root tag type")
+	// ErrLimitExceeded means the input needs more than the configured Limits allow
+	ErrLimitExceeded = errors.New("parse limit exceeded")
+)
+
+type TagParseError interface {
+	isFatal() bool
+	Error() string
+	// Offset is the byte offset in the input at which parsing failed
+	Offset() int64
+	// Path locates the failing tag below the root, such as Level.Sections[3].BlockStates
+	Path() string
+	// TagType is the type of the tag being read when parsing failed
+	TagType() tagTypeByte
+	// Unwrap returns the underlying cause, or nil
+	Unwrap() error
+}
+
+// ParseErrors lists every error found by a lenient parse, in input order
+type ParseErrors []TagParseError
+
+func (e ParseErrors) Error() string {
+	if len(e) == 1 {
+		return e[0].Error()
+	}
+	messages := make([]string, len(e))
+	for i, err := range e {
+		messages[i] = err.Error()
+	}
+	return fmt.Sprintf("%d errors: %s", len(e), strings.Join(messages, "; "))
+}
+
+// Unwrap lets errors.Is and errors.As look through every error in the list
+func (e ParseErrors) Unwrap() []error {
+	errs := make([]error, len(e))
+	for i, err := range e {
+		errs[i] = err
+	}
+	return errs
+}
+
+// parseErrorInfo holds what both kinds of parse errors report
+type parseErrorInfo struct {
+	message string
+	offset  int64
+	path    string
+	tagType tagTypeByte
+	cause   error
+}
+
+func (e parseErrorInfo) Error() string {
+	var b strings.Builder
+	b.WriteString(e.message)
+	if e.cause != nil {
+		b.WriteString(": ")
+		b.WriteString(e.cause.Error())
+	}
+	b.WriteString(" (")
+	if name, ok := TagName[e.tagType]; ok {
+		b.WriteString(name)
+	} else {
+		fmt.Fprintf(&b, "tag type %d", e.tagType)
+	}
+	if e.path != "" {
+		b.WriteString(" at ")
+		b.WriteString(e.path)
+	}
+	fmt.Fprintf(&b, ", offset %d)", e.offset)
+	return b.String()
+}
+func (e parseErrorInfo) Offset() int64 {
+	return e.offset
+}
+func (e parseErrorInfo) Path() string {
+	return e.path
+}
+func (e parseErrorInfo) TagType() tagTypeByte {
+	return e.tagType
+}
+func (e parseErrorInfo) Unwrap() error {
+	return e.cause
+}
+
+// tagParseValueError means a value could not be read, so nothing after it can be trusted
+type tagParseValueError struct {
+	parseErrorInfo
+}
+
+// tagParseArrayError means a value was read completely but is not acceptable,
+// or an array broke off after its earlier elements were read
+type tagParseArrayError struct {
+	parseErrorInfo
+}
+
+func (e tagParseValueError) isFatal() bool {
+	return true
+}
+func (e tagParseArrayError) isFatal() bool {
+	return false
+}
+
+func newParseValueError(message string, offset int64, tagType tagTypeByte, cause error) TagParseError {
+	return tagParseValueError{newParseErrorInfo(message, offset, "", tagType, cause)}
+}
+func newParseArrayError(message string, offset int64, tagType tagTypeByte, cause error) TagParseError {
+	return tagParseArrayError{newParseErrorInfo(message, offset, "", tagType, cause)}
+}
+
+func newParseErrorInfo(message string, offset int64, path string, tagType tagTypeByte, cause error) parseErrorInfo {
+	if cause == io.EOF {
+		// the input can only end cleanly between root tags, which is never reported as an error
+		cause = io.ErrUnexpectedEOF
+	}
+	return parseErrorInfo{message, offset, path, tagType, cause}
+}
+
+// pathSegment is one step from a tag to its child: a compound key, or a list index when index >= 0
+type pathSegment struct {
+	name  string
+	index int
+}
+
+func formatPath(segments []pathSegment) string {
+	var b strings.Builder
+	for _, segment := range segments {
+		if segment.index >= 0 {
+			b.WriteByte('[')
+			b.WriteString(strconv.Itoa(segment.index))
+			b.WriteByte(']')
+			continue
+		}
+		if b.Len() > 0 {
+			b.WriteByte('.')
+		}
+		b.WriteString(formatPathKey(segment.name))
+	}
+	return b.String()
+}
+
+// formatPathKey quotes compound keys that are not plain words, as in Minecraft's NBT paths
+func formatPathKey(name string) string {
+	if name == "" {
+		return `""`
+	}
+	for _, r := range name {
+		if !isPathKeyChar(r) {
+			return quoteSNBT(name)
+		}
+	}
+	return name
+}
+
+func isPathKeyChar(r rune) bool {
+	return r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '_' || r == '-' || r == '+'
+}
@@ -0,0 +1,155 @@
+package nbt
+
+import (
+	"bytes"
+	"errors"
+	"goNbt/lib"
+	"io"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+func appendTestHeader(data []byte, tagType tagTypeByte, name string) []byte {
+	data = append(data, byte(tagType))
+	data = append(data, lib.UInt16ToBytes(uint16(len(name)), true)...)
+	return append(data, []byte(name)...)
+}
+
+// truncatedSectionsData cuts a chunk-like tree in the middle of Level.Sections[1].BlockStates
+func truncatedSectionsData() []byte {
+	data := appendTestHeader(nil, BTagCompound, "")
+	data = appendTestHeader(data, BTagCompound, "Level")
+	data = appendTestHeader(data, BTagList, "Sections")
+	data = append(data, byte(BTagCompound))
+	data = append(data, lib.Int32ToBytes(2, true)...)
+
+	data = appendTestHeader(data, BTagByte, "Y")
+	data = append(data, 0, byte(BTagEnd))
+
+	data = appendTestHeader(data, BTagLongArray, "BlockStates")
+	data = append(data, lib.Int32ToBytes(2, true)...)
+	data = append(data, lib.Int64ToBytes(1, true)...)
+	return append(data, 0, 0, 0)
+}
+
+func TestParseErrorReportsPath(t *testing.T) {
+	data := truncatedSectionsData()
+	_, err := ParseNBT(data, false)
+	if err == nil {
+		t.Fatalf("Expected an error for truncated input")
+	}
+	if err.Path() != "Level.Sections[1].BlockStates" {
+		t.Errorf("Expected path Level.Sections[1].BlockStates, got %q", err.Path())
+	}
+	if err.TagType() != BTagLongArray {
+		t.Errorf("Expected tag type %s, got %s", TagName[BTagLongArray], TagName[err.TagType()])
+	}
+	if err.Offset() != int64(len(data)-3) {
+		t.Errorf("Expected failure at offset %d, got %d", len(data)-3, err.Offset())
+	}
+	if !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Errorf("Expected the cause to be io.ErrUnexpectedEOF, got %v", err.Unwrap())
+	}
+}
+
+func TestParseErrorUnknownTagType(t *testing.T) {
+	data := appendTestHeader(nil, BTagCompound, "")
+	data = appendTestHeader(data, tagTypeByte(13), "minecraft:bad")
+	data = append(data, byte(BTagEnd))
+
+	_, err := ParseNBT(data, false)
+	if !errors.Is(err, ErrUnknownTagType) {
+		t.Fatalf("Expected ErrUnknownTagType, got %v", err)
+	}
+	if err.Path() != `"minecraft:bad"` {
+		t.Errorf(`Expected path "minecraft:bad", got %s`, err.Path())
+	}
+}
+
+func TestParseErrorTrailingData(t *testing.T) {
+	data := append(decoderTestData(), 0xFF)
+	_, err := ParseNBT(data, false)
+	if !errors.Is(err, ErrTrailingData) {
+		t.Fatalf("Expected ErrTrailingData, got %v", err)
+	}
+	if err.isFatal() {
+		t.Errorf("Expected trailing data not to be fatal")
+	}
+	if !strings.Contains(err.Error(), "1 bytes left") {
+		t.Errorf("Expected the error to count the trailing bytes, got %q", err.Error())
+	}
+}
+
+func TestDecoderWarnings(t *testing.T) {
+	data := appendTestHeader(nil, BTagCompound, "")
+	data = appendTestHeader(data, BTagString, "text")
+	// a lone high surrogate
+	data = append(data, lib.UInt16ToBytes(3, true)...)
+	data = append(data, 0xED, 0xA0, 0x80)
+	// an emoji as standard UTF-8, as this library used to write it
+	data = appendTestHeader(data, BTagString, "emoji")
+	data = append(data, lib.UInt16ToBytes(4, true)...)
+	data = append(data, 0xF0, 0x9F, 0x98, 0x80)
+	data = appendTestHeader(data, BTagList, "empty")
+	data = append(data, byte(BTagInt))
+	data = append(data, lib.Int32ToBytes(-1, true)...)
+	data = append(data, byte(BTagEnd))
+
+	var warnings []TagParseError
+	decoder := NewDecoder(bytes.NewReader(data), false)
+	decoder.SetWarningHandler(func(warning TagParseError) {
+		warnings = append(warnings, warning)
+	})
+	tag, err := decoder.Decode()
+	if err != nil {
+		t.Fatalf("Failed to decode NBT: %v", err)
+	}
+	if len(warnings) != 3 {
+		t.Fatalf("Expected 3 warnings, got %d: %v", len(warnings), warnings)
+	}
+	if warnings[0].Path() != "text" || warnings[0].TagType() != BTagString {
+		t.Errorf("Expected a TAG_String warning at text, got %v", warnings[0])
+	}
+	if warnings[1].Path() != "emoji" || warnings[1].TagType() != BTagString {
+		t.Errorf("Expected a TAG_String warning at emoji, got %v", warnings[1])
+	}
+	if warnings[2].Path() != "empty" || warnings[2].TagType() != BTagList {
+		t.Errorf("Expected a TAG_List warning at empty, got %v", warnings[2])
+	}
+	if emoji, _ := tag.(*TagCompound).GetString("emoji"); emoji != "\U0001F600" {
+		t.Errorf("Expected the emoji to be read, got %q", emoji)
+	}
+}
+
+func TestDecoderWrapsReadError(t *testing.T) {
+	errDisk := errors.New("disk failure")
+	r := io.MultiReader(bytes.NewReader(decoderTestData()[:20]), iotest.ErrReader(errDisk))
+
+	_, err := NewDecoder(r, false).Decode()
+	if !errors.Is(err, errDisk) {
+		t.Fatalf("Expected the read error to be wrapped, got %v", err)
+	}
+	var parseErr TagParseError
+	if !errors.As(err, &parseErr) {
+		t.Fatalf("Expected a TagParseError, got %T", err)
+	}
+	if parseErr.Path() != "title" {
+		t.Errorf("Expected path title, got %q", parseErr.Path())
+	}
+}
+
+func TestPullParserErrorPath(t *testing.T) {
+	p := NewPullParser(truncatedSectionsData(), false)
+	var err error
+	for err == nil {
+		_, err = p.Next()
+	}
+	var parseErr TagParseError
+	if !errors.As(err, &parseErr) {
+		t.Fatalf("Expected a TagParseError, got %v", err)
+	}
+	if parseErr.Path() != "Level.Sections[1].BlockStates" {
+		t.Errorf("Expected path Level.Sections[1].BlockStates, got %q", parseErr.Path())
+	}
+}
@@ -18,34 +18,39 @@ var TagName = map[tagTypeByte]string{
 	BTagLongArray: "TAG_Long_Array",
 }
 
+// PrintTag prints a tree tag by tag, each with its type, name, data length and value.
+// Compound children and list elements follow their parent, which ends with a blank line after them.
 func PrintTag(tag NBTTag) {
-	fmt.Println("Tag Type:", TagName[tag.Type()])
-	fmt.Println("Tag Name:", tag.Name())
-	fmt.Println("Tag Data Length:", tag.DataLength())
-	fmt.Println("Z index:", tag.ZIndex())
-	switch tag.Type() {
-	case BTagByte:
-		fmt.Println("Tag Value:", tag.(*TagByte).Value)
-	case BTagShort:
-		fmt.Println("Tag Value:", tag.(*TagShort).Value)
-	case BTagInt:
-		fmt.Println("Tag Value:", tag.(*TagInt).Value)
-	case BTagLong:
-		fmt.Println("Tag Value:", tag.(*TagLong).Value)
-	case BTagFloat:
-		fmt.Println("Tag Value:", tag.(*TagFloat).Value)
-	case BTagDouble:
-		fmt.Println("Tag Value:", tag.(*TagDouble).Value)
-	case BTagString:
-		fmt.Println("Tag Value:", tag.(*TagString).Value)
-	case BTagCompound:
-		{
-			for _, childTag := range tag.(*TagCompound).Value {
-				PrintTag(childTag)
-			}
+	WalkOrder(tag, func(path string, tag NBTTag) error {
+		fmt.Println("Tag Type:", TagName[tag.Type()])
+		fmt.Println("Tag Name:", tag.Name())
+		fmt.Println("Tag Data Length:", tag.DataLength())
+		switch tag := tag.(type) {
+		case *TagByte:
+			fmt.Println("Tag Value:", tag.Value)
+		case *TagShort:
+			fmt.Println("Tag Value:", tag.Value)
+		case *TagInt:
+			fmt.Println("Tag Value:", tag.Value)
+		case *TagLong:
+			fmt.Println("Tag Value:", tag.Value)
+		case *TagFloat:
+			fmt.Println("Tag Value:", tag.Value)
+		case *TagDouble:
+			fmt.Println("Tag Value:", tag.Value)
+		case *TagString:
+			fmt.Println("Tag Value:", tag.Value)
+		case *TagByteArray, *TagIntArray, *TagLongArray:
+			values, _ := integerValues(tag)
+			fmt.Println("Tag Value:", values)
+			// the elements are printed as one value
+			return SkipChildren
+		case *TagList:
+			fmt.Println("Tag Element Type:", TagName[tag.ElementType])
 		}
-	default:
-		fmt.Println("Tag Value: [Not Implemented]")
-	}
-	fmt.Println()
+		return nil
+	}, func(path string, tag NBTTag) error {
+		fmt.Println()
+		return nil
+	})
 }
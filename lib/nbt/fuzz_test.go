@@ -0,0 +1,66 @@
+package nbt
+
+import (
+	"bytes"
+	"testing"
+)
+
+func FuzzParseNBT(f *testing.F) {
+	f.Add(decoderTestData())
+	f.Add(networkTestData())
+	f.Add(levelDatTestData(10))
+	f.Add(truncatedSectionsData())
+	f.Add(nestedListData(8))
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		for _, encoding := range []Encoding{EncodingJava, EncodingBedrock, EncodingBedrockNetwork} {
+			tag, err := ParseNBTEncoding(data, encoding)
+			ParseNBTNamelessRoot(data, encoding)
+
+			// partial trees from lenient parsing must still serialize
+			if partial, _ := ParseNBTWithOptions(data, ParseOptions{Encoding: encoding, Lenient: true}); partial != nil {
+				if _, err := SerializeNBTEncoding(partial, encoding); err != nil {
+					t.Fatalf("%s: failed to serialize a partial tree: %v", encoding, err)
+				}
+			}
+
+			decoder := NewDecoder(bytes.NewReader(data), false)
+			decoder.SetEncoding(encoding)
+			for {
+				if _, err := decoder.Decode(); err != nil {
+					break
+				}
+			}
+
+			p := NewPullParser(data, false)
+			p.SetEncoding(encoding)
+			for i := 0; ; i++ {
+				token, err := p.Next()
+				if err != nil {
+					break
+				}
+				// alternate between reading and skipping containers
+				if token.Kind == TokenBeginCompound && i%2 == 1 {
+					p.Skip()
+				}
+			}
+
+			if err != nil {
+				continue
+			}
+			// whatever parses must serialize, and serializing must be stable from then on
+			first, serializeErr := SerializeNBTEncoding(tag, encoding)
+			if serializeErr != nil {
+				t.Fatalf("%s: failed to serialize a parsed tag: %v", encoding, serializeErr)
+			}
+			reparsed, err := ParseNBTEncoding(first, encoding)
+			if err != nil {
+				t.Fatalf("%s: failed to parse serialized data: %v", encoding, err)
+			}
+			second, _ := SerializeNBTEncoding(reparsed, encoding)
+			if !bytes.Equal(first, second) {
+				t.Fatalf("%s: serialization is not stable:\n%x\n%x", encoding, first, second)
+			}
+		}
+	})
+}
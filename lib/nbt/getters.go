@@ -0,0 +1,152 @@
+package nbt
+
+// Typed getters look up a child by name and report false if it is missing or of another type.
+// Numbers widen like in Java: a getter accepts its own type and every narrower one,
+// so GetInt also reads a TAG_Byte or TAG_Short, and GetDouble reads any number.
+// A nil compound reports every child as missing, so lookups can be chained:
+//
+//	data, _ := root.GetCompound("Data")
+//	player, _ := data.GetCompound("Player")
+//	xp := player.GetIntOr("XpLevel", 0)
+
+func (t *TagCompound) GetByte(name string) (byte, bool) {
+	if tag, ok := t.Get(name).(*TagByte); ok {
+		return tag.Value, true
+	}
+	return 0, false
+}
+
+func (t *TagCompound) GetShort(name string) (int16, bool) {
+	i, ok := t.getInteger(name, BTagShort)
+	return int16(i), ok
+}
+
+func (t *TagCompound) GetInt(name string) (int32, bool) {
+	i, ok := t.getInteger(name, BTagInt)
+	return int32(i), ok
+}
+
+func (t *TagCompound) GetLong(name string) (int64, bool) {
+	return t.getInteger(name, BTagLong)
+}
+
+func (t *TagCompound) GetFloat(name string) (float32, bool) {
+	f, ok := t.getFloat(name, BTagFloat)
+	return float32(f), ok
+}
+
+func (t *TagCompound) GetDouble(name string) (float64, bool) {
+	return t.getFloat(name, BTagDouble)
+}
+
+func (t *TagCompound) GetString(name string) (string, bool) {
+	if tag, ok := t.Get(name).(*TagString); ok {
+		return tag.Value, true
+	}
+	return "", false
+}
+
+func (t *TagCompound) GetByteArray(name string) ([]byte, bool) {
+	if tag, ok := t.Get(name).(*TagByteArray); ok {
+		return tag.Value, true
+	}
+	return nil, false
+}
+
+func (t *TagCompound) GetIntArray(name string) ([]int32, bool) {
+	if tag, ok := t.Get(name).(*TagIntArray); ok {
+		return tag.Value, true
+	}
+	return nil, false
+}
+
+func (t *TagCompound) GetLongArray(name string) ([]int64, bool) {
+	if tag, ok := t.Get(name).(*TagLongArray); ok {
+		return tag.Value, true
+	}
+	return nil, false
+}
+
+func (t *TagCompound) GetList(name string) (*TagList, bool) {
+	tag, ok := t.Get(name).(*TagList)
+	return tag, ok
+}
+
+func (t *TagCompound) GetCompound(name string) (*TagCompound, bool) {
+	tag, ok := t.Get(name).(*TagCompound)
+	return tag, ok
+}
+
+// The Or variants return defaultValue where the getter would report false
+
+func (t *TagCompound) GetByteOr(name string, defaultValue byte) byte {
+	if value, ok := t.GetByte(name); ok {
+		return value
+	}
+	return defaultValue
+}
+
+func (t *TagCompound) GetShortOr(name string, defaultValue int16) int16 {
+	if value, ok := t.GetShort(name); ok {
+		return value
+	}
+	return defaultValue
+}
+
+func (t *TagCompound) GetIntOr(name string, defaultValue int32) int32 {
+	if value, ok := t.GetInt(name); ok {
+		return value
+	}
+	return defaultValue
+}
+
+func (t *TagCompound) GetLongOr(name string, defaultValue int64) int64 {
+	if value, ok := t.GetLong(name); ok {
+		return value
+	}
+	return defaultValue
+}
+
+func (t *TagCompound) GetFloatOr(name string, defaultValue float32) float32 {
+	if value, ok := t.GetFloat(name); ok {
+		return value
+	}
+	return defaultValue
+}
+
+func (t *TagCompound) GetDoubleOr(name string, defaultValue float64) float64 {
+	if value, ok := t.GetDouble(name); ok {
+		return value
+	}
+	return defaultValue
+}
+
+func (t *TagCompound) GetStringOr(name string, defaultValue string) string {
+	if value, ok := t.GetString(name); ok {
+		return value
+	}
+	return defaultValue
+}
+
+// getInteger reads an integer child of type widest or narrower
+func (t *TagCompound) getInteger(name string, widest tagTypeByte) (int64, bool) {
+	tag := t.Get(name)
+	if tag == nil || tag.Type() > widest {
+		return 0, false
+	}
+	i, _, isFloat, ok := numericValue(tag)
+	return i, ok && !isFloat
+}
+
+// getFloat reads a number child of type widest or narrower, integers included
+func (t *TagCompound) getFloat(name string, widest tagTypeByte) (float64, bool) {
+	tag := t.Get(name)
+	if tag == nil || tag.Type() > widest {
+		return 0, false
+	}
+	i, f, isFloat, ok := numericValue(tag)
+	if !isFloat {
+		f = float64(i)
+	}
+	return f, ok
+}
@@ -0,0 +1,99 @@
+package nbt
+
+import (
+	"slices"
+	"testing"
+)
+
+func playerTestData() *TagCompound {
+	return NewCompoundBuilder("").
+		Compound("Data").
+		Compound("Player").
+		Int("XpLevel", 30).
+		Byte("OnGround", 1).
+		Byte("Dimension", 0xFF).
+		Float("XpP", 0.5).
+		String("Name", "Steve").
+		IntArray("UUID", []int32{1, 2, 3, 4}).
+		List("Pos", BTagDouble, NewDouble("", 1), NewDouble("", 64), NewDouble("", -3)).
+		Build()
+}
+
+func TestTypedGetters(t *testing.T) {
+	root := playerTestData()
+	data, ok := root.GetCompound("Data")
+	if !ok {
+		t.Fatalf("Expected a Data compound")
+	}
+	player, _ := data.GetCompound("Player")
+
+	if xp, ok := player.GetInt("XpLevel"); !ok || xp != 30 {
+		t.Errorf("Expected XpLevel 30, got %d (found: %v)", xp, ok)
+	}
+	if name, ok := player.GetString("Name"); !ok || name != "Steve" {
+		t.Errorf("Expected Name Steve, got %q (found: %v)", name, ok)
+	}
+	if uuid, ok := player.GetIntArray("UUID"); !ok || !slices.Equal(uuid, []int32{1, 2, 3, 4}) {
+		t.Errorf("Expected UUID [1 2 3 4], got %v (found: %v)", uuid, ok)
+	}
+	if pos, ok := player.GetList("Pos"); !ok || len(pos.Value) != 3 {
+		t.Errorf("Expected a Pos list of 3, got %v (found: %v)", pos, ok)
+	}
+
+	// the wrong type is reported like a missing child
+	if _, ok := player.GetString("XpLevel"); ok {
+		t.Errorf("Expected GetString to reject a TAG_Int")
+	}
+	if _, ok := player.GetCompound("Name"); ok {
+		t.Errorf("Expected GetCompound to reject a TAG_String")
+	}
+}
+
+func TestTypedGettersWiden(t *testing.T) {
+	data, _ := playerTestData().GetCompound("Data")
+	player, _ := data.GetCompound("Player")
+
+	if onGround, ok := player.GetInt("OnGround"); !ok || onGround != 1 {
+		t.Errorf("Expected OnGround to read as int 1, got %d (found: %v)", onGround, ok)
+	}
+	if dimension, ok := player.GetLong("Dimension"); !ok || dimension != -1 {
+		t.Errorf("Expected the signed byte Dimension to widen to -1, got %d (found: %v)", dimension, ok)
+	}
+	if xp, ok := player.GetDouble("XpLevel"); !ok || xp != 30 {
+		t.Errorf("Expected XpLevel to read as double 30, got %v (found: %v)", xp, ok)
+	}
+	if progress, ok := player.GetDouble("XpP"); !ok || progress != 0.5 {
+		t.Errorf("Expected XpP to read as double 0.5, got %v (found: %v)", progress, ok)
+	}
+
+	// narrowing is not allowed
+	if _, ok := player.GetShort("XpLevel"); ok {
+		t.Errorf("Expected GetShort to reject a TAG_Int")
+	}
+	if _, ok := player.GetInt("XpP"); ok {
+		t.Errorf("Expected GetInt to reject a TAG_Float")
+	}
+}
+
+func TestTypedGettersOr(t *testing.T) {
+	root := playerTestData()
+	data, _ := root.GetCompound("Data")
+	player, _ := data.GetCompound("Player")
+
+	if xp := player.GetIntOr("XpLevel", -1); xp != 30 {
+		t.Errorf("Expected XpLevel 30, got %d", xp)
+	}
+	if score := player.GetIntOr("Score", 7); score != 7 {
+		t.Errorf("Expected the default 7 for a missing Score, got %d", score)
+	}
+	if name := player.GetStringOr("XpLevel", "none"); name != "none" {
+		t.Errorf("Expected the default for a TAG_Int read as string, got %q", name)
+	}
+
+	// missing compounds along the way read as empty
+	missing, _ := root.GetCompound("Missing")
+	nested, _ := missing.GetCompound("Player")
+	if level := nested.GetIntOr("XpLevel", 5); level != 5 {
+		t.Errorf("Expected the default through missing compounds, got %d", level)
+	}
+}
@@ -287,12 +287,16 @@ func (t *TagLongArray) UnmarshalJSON(data []byte) error {
 
 // MarshalJSON implements json.Marshaler for TagList
 func (t *TagList) MarshalJSON() ([]byte, error) {
-	return json.Marshal(map[string]any{
+	fields := map[string]any{
 		"type":        "list",
 		"name":        t.name,
 		"elementType": tagTypeToString(t.ElementType),
 		"value":       t.Value,
-	})
+	}
+	if t.Truncated {
+		fields["truncated"] = true
+	}
+	return json.Marshal(fields)
 }
 
 // UnmarshalJSON implements json.Unmarshaler for TagList
@@ -302,6 +306,7 @@ func (t *TagList) UnmarshalJSON(data []byte) error {
 		Name        string            `json:"name"`
 		ElementType string            `json:"elementType"`
 		Value       []json.RawMessage `json:"value"`
+		Truncated   bool              `json:"truncated"`
 	}
 	if err := json.Unmarshal(data, &temp); err != nil {
 		return err
@@ -313,6 +318,7 @@ func (t *TagList) UnmarshalJSON(data []byte) error {
 	t.tagType = BTagList
 	t.name = temp.Name
 	t.ElementType = stringToTagType(temp.ElementType)
+	t.Truncated = temp.Truncated
 
 	t.Value = make([]NBTTag, len(temp.Value))
 	for i, rawTag := range temp.Value {
@@ -328,19 +334,24 @@ func (t *TagList) UnmarshalJSON(data []byte) error {
 
 // MarshalJSON implements json.Marshaler for TagCompound
 func (t *TagCompound) MarshalJSON() ([]byte, error) {
-	return json.Marshal(map[string]any{
+	fields := map[string]any{
 		"type":  "compound",
 		"name":  t.name,
 		"value": t.Value,
-	})
+	}
+	if t.Truncated {
+		fields["truncated"] = true
+	}
+	return json.Marshal(fields)
 }
 
 // UnmarshalJSON implements json.Unmarshaler for TagCompound
 func (t *TagCompound) UnmarshalJSON(data []byte) error {
 	var temp struct {
-		Type  string            `json:"type"`
-		Name  string            `json:"name"`
-		Value []json.RawMessage `json:"value"`
+		Type      string            `json:"type"`
+		Name      string            `json:"name"`
+		Value     []json.RawMessage `json:"value"`
+		Truncated bool              `json:"truncated"`
 	}
 	if err := json.Unmarshal(data, &temp); err != nil {
 		return err
@@ -351,6 +362,7 @@ func (t *TagCompound) UnmarshalJSON(data []byte) error {
 
 	t.tagType = BTagCompound
 	t.name = temp.Name
+	t.Truncated = temp.Truncated
 
 	t.Value = make([]NBTTag, len(temp.Value))
 	for i, rawTag := range temp.Value {
@@ -0,0 +1,119 @@
+package nbt
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"goNbt/lib"
+	"io"
+	"testing"
+)
+
+func TestLenientKeepsPartialTree(t *testing.T) {
+	tag, err := ParseNBTWithOptions(truncatedSectionsData(), ParseOptions{Lenient: true})
+	var parseErrors ParseErrors
+	if !errors.As(err, &parseErrors) {
+		t.Fatalf("Expected ParseErrors, got %v", err)
+	}
+	if len(parseErrors) != 1 || parseErrors[0].Path() != "Level.Sections[1].BlockStates" {
+		t.Errorf("Expected one error at Level.Sections[1].BlockStates, got %v", parseErrors)
+	}
+	if !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Errorf("Expected the errors to wrap io.ErrUnexpectedEOF")
+	}
+
+	root := tag.(*TagCompound)
+	level := root.Value[0].(*TagCompound)
+	sections := level.Value[0].(*TagList)
+	if !root.Truncated || !level.Truncated || !sections.Truncated {
+		t.Errorf("Expected every container up to the failure to be truncated")
+	}
+	if len(sections.Value) != 2 {
+		t.Fatalf("Expected 2 sections, got %d", len(sections.Value))
+	}
+	first := sections.Value[0].(*TagCompound)
+	if first.Truncated || first.Value[0].(*TagByte).Name() != "Y" {
+		t.Errorf("Expected the first section to be complete, got %v", first.Value)
+	}
+	second := sections.Value[1].(*TagCompound)
+	if !second.Truncated || len(second.Value) != 1 || second.Value[0].Type() != BTagEnd {
+		t.Errorf("Expected the second section to be truncated and closed, got %v", second.Value)
+	}
+
+	if _, err := SerializeNBT(tag, false); err != nil {
+		t.Errorf("Expected the partial tree to serialize, got %v", err)
+	}
+}
+
+func TestLenientReportsDecompressionError(t *testing.T) {
+	var compressed bytes.Buffer
+	zip := lib.NewZipWriter(&compressed, "gzip")
+	zip.Write(decoderTestData())
+	zip.Close()
+	data := compressed.Bytes()
+	data[len(data)-8] ^= 0xFF // the CRC-32 of the gzip trailer
+
+	opts := ParseOptions{Compression: CompressionGzip}
+	if _, err := ParseNBTWithOptions(data, opts); err == nil {
+		t.Fatalf("Expected strict parsing to fail")
+	}
+	opts.Lenient = true
+	tag, err := ParseNBTWithOptions(data, opts)
+	var parseErrors ParseErrors
+	if !errors.As(err, &parseErrors) || len(parseErrors) != 1 || !errors.Is(err, gzip.ErrChecksum) {
+		t.Fatalf("Expected the checksum error in ParseErrors, got %v", err)
+	}
+	if root, ok := tag.(*TagCompound); !ok || !root.Truncated {
+		t.Errorf("Expected the salvaged root to be marked truncated, got %v", tag)
+	}
+}
+
+func TestLenientCollectsRecoverableErrors(t *testing.T) {
+	data := appendTestHeader(nil, BTagCompound, "")
+	data = appendTestHeader(data, BTagString, "text")
+	data = append(data, lib.UInt16ToBytes(1, true)...)
+	data = append(data, 0xFF) // never valid in Modified UTF-8
+	data = appendTestHeader(data, BTagIntArray, "ints")
+	data = append(data, lib.Int32ToBytes(-4, true)...)
+	data = appendTestHeader(data, BTagList, "ends")
+	data = append(data, byte(BTagEnd))
+	data = append(data, lib.Int32ToBytes(3, true)...)
+	data = appendTestHeader(data, BTagInt, "after")
+	data = append(data, lib.Int32ToBytes(42, true)...)
+	data = append(data, byte(BTagEnd), 0xAB)
+
+	if _, err := ParseNBT(data, false); err == nil {
+		t.Fatalf("Expected strict parsing to fail")
+	}
+
+	tag, err := ParseNBTWithOptions(data, ParseOptions{Lenient: true})
+	var parseErrors ParseErrors
+	if !errors.As(err, &parseErrors) {
+		t.Fatalf("Expected ParseErrors, got %v", err)
+	}
+	expectedPaths := []string{"text", "ints", "ends", ""}
+	if len(parseErrors) != len(expectedPaths) {
+		t.Fatalf("Expected %d errors, got %d: %v", len(expectedPaths), len(parseErrors), parseErrors)
+	}
+	for i, path := range expectedPaths {
+		if parseErrors[i].Path() != path {
+			t.Errorf("Expected error %d at %q, got %v", i, path, parseErrors[i])
+		}
+	}
+	if !errors.Is(err, ErrInvalidMUTF8) || !errors.Is(err, ErrNegativeLength) || !errors.Is(err, ErrTrailingData) {
+		t.Errorf("Expected every cause to be reachable through errors.Is")
+	}
+
+	root := tag.(*TagCompound)
+	if root.Truncated {
+		t.Errorf("Expected recoverable errors not to truncate the root")
+	}
+	if after := root.Value[3].(*TagInt); after.Value != 42 {
+		t.Errorf("Expected the tag after the errors to be read, got %d", after.Value)
+	}
+
+	decoder, _ := NewDecoderWithOptions(bytes.NewReader(data), ParseOptions{Lenient: true})
+	if _, err := decoder.Decode(); !errors.As(err, &parseErrors) || len(parseErrors) != 3 {
+		t.Errorf("Expected the decoder to collect 3 errors before the trailing data, got %v", err)
+	}
+}
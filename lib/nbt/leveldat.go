@@ -0,0 +1,87 @@
+package nbt
+
+import (
+	"bytes"
+	"encoding/binary"
+)
+
+// Bedrock level.dat files start with an 8 byte header before the little-endian NBT:
+// the storage version, then the length of the NBT that follows, both little-endian int32.
+const levelHeaderSize = 8
+
+// LevelDat is a Bedrock level.dat file
+type LevelDat struct {
+	// StorageVersion is the first field of the header, 0 if the file had no header
+	StorageVersion int32
+	Root           NBTTag
+}
+
+// hasLevelHeader reports whether data starts with a level.dat header whose length matches the rest of data
+func hasLevelHeader(data []byte) bool {
+	if len(data) <= levelHeaderSize {
+		return false
+	}
+	length := binary.LittleEndian.Uint32(data[4:levelHeaderSize])
+	return int64(length) == int64(len(data)-levelHeaderSize)
+}
+
+// looksLikeLevelHeader detects a level.dat header from the first levelHeaderSize+1 bytes of a stream,
+// where the total length is unknown.
+//
+// A headerless root with the same first 4 bytes would be an empty compound or a list of TAG_End,
+// so bytes after it can only be a header followed by the real root.
+func looksLikeLevelHeader(peek []byte) bool {
+	if len(peek) <= levelHeaderSize {
+		return false
+	}
+	if peek[1] != 0 || peek[2] != 0 || peek[3] != 0 {
+		return false
+	}
+	rootType := tagTypeByte(peek[levelHeaderSize])
+	return (rootType == BTagCompound || rootType == BTagList) && binary.LittleEndian.Uint32(peek[4:levelHeaderSize]) > 0
+}
+
+// ReadLevelHeader returns the storage version from the header of a Bedrock level.dat,
+// or false if data does not start with a header whose length matches the rest of data.
+// The parse functions skip the header when parsing EncodingBedrock with a named root,
+// so this is how to keep it for writing the file back, see AddLevelHeader.
+func ReadLevelHeader(data []byte) (storageVersion int32, ok bool) {
+	if !hasLevelHeader(data) {
+		return 0, false
+	}
+	return int32(binary.LittleEndian.Uint32(data[0:4])), true
+}
+
+// AddLevelHeader returns the little-endian NBT in data preceded by a level.dat header
+func AddLevelHeader(storageVersion int32, data []byte) []byte {
+	header := make([]byte, levelHeaderSize, levelHeaderSize+len(data))
+	binary.LittleEndian.PutUint32(header[0:4], uint32(storageVersion))
+	binary.LittleEndian.PutUint32(header[4:levelHeaderSize], uint32(len(data)))
+	return append(header, data...)
+}
+
+// ParseBedrockLevelDat parses a Bedrock level.dat, with or without its header.
+func ParseBedrockLevelDat(data []byte) (*LevelDat, TagParseError) {
+	level := &LevelDat{}
+	level.StorageVersion, _ = ReadLevelHeader(data)
+	// parseRoot skips the header itself
+	root, err := parseRoot(data, ParseOptions{Encoding: EncodingBedrock})
+	if err != nil {
+		return nil, err
+	}
+	level.Root = root
+	return level, nil
+}
+
+// SerializeBedrockLevelDat writes the header, with the length recomputed from the serialized root, followed by the root tag.
+func SerializeBedrockLevelDat(level *LevelDat) ([]byte, error) {
+	var buf bytes.Buffer
+	buf.Write(make([]byte, levelHeaderSize))
+	if err := newEncoder(&buf, EncodingBedrock).writeRoot(level.Root); err != nil {
+		return nil, err
+	}
+	data := buf.Bytes()
+	binary.LittleEndian.PutUint32(data[0:4], uint32(level.StorageVersion))
+	binary.LittleEndian.PutUint32(data[4:levelHeaderSize], uint32(len(data)-levelHeaderSize))
+	return data, nil
+}
@@ -0,0 +1,94 @@
+package nbt
+
+import (
+	"bytes"
+	"encoding/binary"
+	"goNbt/lib"
+	"testing"
+)
+
+func levelDatTestData(storageVersion int32) []byte {
+	body := []byte{byte(BTagCompound)}
+	body = append(body, lib.UInt16ToBytes(0, false)...)
+	body = append(body, byte(BTagString))
+	body = append(body, lib.UInt16ToBytes(9, false)...)
+	body = append(body, []byte("LevelName")...)
+	body = append(body, lib.UInt16ToBytes(5, false)...)
+	body = append(body, []byte("World")...)
+	body = append(body, byte(BTagEnd))
+
+	data := lib.Int32ToBytes(storageVersion, false)
+	data = append(data, lib.Int32ToBytes(int32(len(body)), false)...)
+	return append(data, body...)
+}
+
+func TestParseNBTDetectsLevelHeader(t *testing.T) {
+	tag, err := ParseNBT(levelDatTestData(10), true)
+	if err != nil {
+		t.Fatalf("Failed to parse level.dat: %v", err)
+	}
+	name := tag.(*TagCompound).Value[0].(*TagString)
+	if name.Value != "World" {
+		t.Errorf("Expected LevelName 'World', got '%s'", name.Value)
+	}
+}
+
+func TestBedrockLevelDatRoundTrip(t *testing.T) {
+	data := levelDatTestData(10)
+	level, err := ParseBedrockLevelDat(data)
+	if err != nil {
+		t.Fatalf("Failed to parse level.dat: %v", err)
+	}
+	if level.StorageVersion != 10 {
+		t.Errorf("Expected storage version 10, got %d", level.StorageVersion)
+	}
+
+	serialized, serializeErr := SerializeBedrockLevelDat(level)
+	if serializeErr != nil {
+		t.Fatalf("Failed to serialize level.dat: %v", serializeErr)
+	}
+	if !bytes.Equal(serialized, data) {
+		t.Errorf("level.dat round trip mismatch.\nGot:      % x\nExpected: % x", serialized, data)
+	}
+
+	// the header length follows edits to the tree
+	level.Root.(*TagCompound).Value[0].(*TagString).Value = "Renamed World"
+	serialized, serializeErr = SerializeBedrockLevelDat(level)
+	if serializeErr != nil {
+		t.Fatalf("Failed to serialize level.dat: %v", serializeErr)
+	}
+	if length := binary.LittleEndian.Uint32(serialized[4:8]); int(length) != len(serialized)-8 {
+		t.Errorf("Expected header length %d, got %d", len(serialized)-8, length)
+	}
+}
+
+func TestLevelHeaderHelpers(t *testing.T) {
+	data := levelDatTestData(9)
+	version, ok := ReadLevelHeader(data)
+	if !ok || version != 9 {
+		t.Fatalf("Expected storage version 9, got %d, %v", version, ok)
+	}
+	if !bytes.Equal(AddLevelHeader(version, data[8:]), data) {
+		t.Errorf("Expected AddLevelHeader to restore the header")
+	}
+	if _, ok := ReadLevelHeader(data[8:]); ok {
+		t.Errorf("Expected no header on the bare NBT")
+	}
+}
+
+func TestDecoderDetectsLevelHeader(t *testing.T) {
+	decoder := NewDecoder(bytes.NewReader(levelDatTestData(9)), true)
+	if _, err := decoder.Decode(); err != nil {
+		t.Fatalf("Failed to decode level.dat: %v", err)
+	}
+	if version, ok := decoder.StorageVersion(); !ok || version != 9 {
+		t.Errorf("Expected storage version 9, got %d (header found: %v)", version, ok)
+	}
+
+	// a header whose length disagrees with the NBT is an error
+	data := levelDatTestData(9)
+	binary.LittleEndian.PutUint32(data[4:8], 3)
+	if _, err := NewDecoder(bytes.NewReader(data), true).Decode(); err == nil {
+		t.Errorf("Expected an error for a mismatched header length")
+	}
+}
@@ -0,0 +1,67 @@
+package nbt
+
+import "fmt"
+
+// Limits bounds the resources a parse may use, so that untrusted input
+// can neither exhaust memory nor nest deep enough to overflow the stack.
+// A zero field means no limit.
+type Limits struct {
+	// MaxDepth is the deepest nesting of compounds and lists, counting a container root as 1.
+	// A negative MaxDepth also means no limit, and is the way to turn it off when the other
+	// fields are zero, as zero Limits in ParseOptions stand for DefaultLimits.
+	MaxDepth int
+	// MaxArrayLength is the largest element count accepted for lists and arrays
+	MaxArrayLength int
+	// MaxAllocation is the approximate number of bytes the parsed tree may occupy,
+	// counting every tag, name, string and array
+	MaxAllocation int64
+}
+
+// DefaultLimits only bounds the nesting depth, to the same 512 levels as Minecraft.
+// Without a MaxAllocation, memory use still grows only as fast as input is read.
+var DefaultLimits = Limits{MaxDepth: 512}
+
+const (
+	// tagOverhead approximates the memory used by a tag struct and its slot in the parent
+	tagOverhead = 64
+	// maxPrealloc caps how many elements are allocated ahead of reading them,
+	// so a corrupt length cannot allocate more than the input can fill
+	maxPrealloc = 1024
+)
+
+// enter records that a compound or list of the given type is being opened
+func (p *parser) enter(tagType tagTypeByte) TagParseError {
+	p.depth++
+	if p.limits.MaxDepth > 0 && p.depth > p.limits.MaxDepth {
+		message := fmt.Sprintf("nesting exceeds the maximum depth of %d", p.limits.MaxDepth)
+		return p.valueError(tagType, message, ErrLimitExceeded)
+	}
+	return nil
+}
+
+func (p *parser) leave() {
+	p.depth--
+}
+
+// checkLength validates the element count read for a list or array
+func (p *parser) checkLength(tagType tagTypeByte, length int32) TagParseError {
+	if length < 0 {
+		// nothing was read for the elements, so the input is still in sync
+		return p.arrayError(tagType, "failed to parse array size", ErrNegativeLength)
+	}
+	if p.limits.MaxArrayLength > 0 && int64(length) > int64(p.limits.MaxArrayLength) {
+		message := fmt.Sprintf("length %d exceeds the maximum array length of %d", length, p.limits.MaxArrayLength)
+		return p.valueError(tagType, message, ErrLimitExceeded)
+	}
+	return nil
+}
+
+// charge accounts for size bytes about to be allocated for the tree
+func (p *parser) charge(tagType tagTypeByte, size int64) TagParseError {
+	p.allocated += size
+	if p.limits.MaxAllocation > 0 && p.allocated > p.limits.MaxAllocation {
+		message := fmt.Sprintf("tree exceeds the maximum allocation of %d bytes", p.limits.MaxAllocation)
+		return p.valueError(tagType, message, ErrLimitExceeded)
+	}
+	return nil
+}
@@ -0,0 +1,103 @@
+package nbt
+
+import (
+	"bytes"
+	"errors"
+	"goNbt/lib"
+	"io"
+	"testing"
+)
+
+// nestedListData nests depth lists of lists inside a root compound
+func nestedListData(depth int) []byte {
+	data := appendTestHeader(nil, BTagCompound, "")
+	data = appendTestHeader(data, BTagList, "nested")
+	for range depth - 1 {
+		data = append(data, byte(BTagList))
+		data = append(data, lib.Int32ToBytes(1, true)...)
+	}
+	data = append(data, byte(BTagEnd))
+	data = append(data, lib.Int32ToBytes(0, true)...)
+	return append(data, byte(BTagEnd))
+}
+
+func TestParseMaxDepth(t *testing.T) {
+	if _, err := ParseNBT(nestedListData(500), false); err != nil {
+		t.Fatalf("Expected 501 levels to parse, got %v", err)
+	}
+	_, err := ParseNBT(nestedListData(DefaultLimits.MaxDepth), false)
+	if !errors.Is(err, ErrLimitExceeded) {
+		t.Fatalf("Expected ErrLimitExceeded, got %v", err)
+	}
+
+	if _, err := ParseNBTWithOptions(nestedListData(1000), ParseOptions{Limits: Limits{MaxDepth: -1}}); err != nil {
+		t.Errorf("Expected a negative MaxDepth to turn off the depth limit, got %v", err)
+	}
+
+	decoder := NewDecoder(bytes.NewReader(nestedListData(10)), false)
+	decoder.SetLimits(Limits{MaxDepth: 8})
+	if _, err := decoder.Decode(); !errors.Is(err, ErrLimitExceeded) {
+		t.Errorf("Expected ErrLimitExceeded from the decoder, got %v", err)
+	}
+
+	p := NewPullParser(nestedListData(10), false)
+	p.SetLimits(Limits{MaxDepth: 8})
+	var pullErr error
+	for pullErr == nil {
+		_, pullErr = p.Next()
+	}
+	if !errors.Is(pullErr, ErrLimitExceeded) {
+		t.Errorf("Expected ErrLimitExceeded from the pull parser, got %v", pullErr)
+	}
+}
+
+func TestParseMaxArrayLengthAndAllocation(t *testing.T) {
+	data := decoderTestData()
+
+	decoder := NewDecoder(bytes.NewReader(data), false)
+	decoder.SetLimits(Limits{MaxArrayLength: 2})
+	if _, err := decoder.Decode(); !errors.Is(err, ErrLimitExceeded) {
+		t.Errorf("Expected the 3 byte array to exceed the limit, got %v", err)
+	}
+
+	decoder = NewDecoder(bytes.NewReader(data), false)
+	decoder.SetLimits(Limits{MaxAllocation: 100})
+	if _, err := decoder.Decode(); !errors.Is(err, ErrLimitExceeded) {
+		t.Errorf("Expected the tree to exceed 100 bytes, got %v", err)
+	}
+
+	decoder = NewDecoder(bytes.NewReader(data), false)
+	decoder.SetLimits(Limits{MaxArrayLength: 3, MaxAllocation: 1 << 20})
+	if _, err := decoder.Decode(); err != nil {
+		t.Errorf("Expected the tree to fit the limits, got %v", err)
+	}
+}
+
+func TestParseRejectsCorruptLengths(t *testing.T) {
+	tests := []struct {
+		name    string
+		payload []byte
+		cause   error
+	}{
+		{"negative int array", append([]byte{byte(BTagIntArray)}, lib.Int32ToBytes(-1, true)...), ErrNegativeLength},
+		{"negative byte array", append([]byte{byte(BTagByteArray)}, lib.Int32ToBytes(-5, true)...), ErrNegativeLength},
+		{"huge long array", append([]byte{byte(BTagLongArray)}, lib.Int32ToBytes(1<<30, true)...), io.ErrUnexpectedEOF},
+		{"huge byte array", append([]byte{byte(BTagByteArray)}, lib.Int32ToBytes(1<<30, true)...), io.ErrUnexpectedEOF},
+		{"list of TAG_End", append([]byte{byte(BTagList), byte(BTagEnd)}, lib.Int32ToBytes(1<<30, true)...), nil},
+	}
+	for _, test := range tests {
+		data := appendTestHeader(nil, BTagCompound, "")
+		data = appendTestHeader(data, tagTypeByte(test.payload[0]), "value")
+		data = append(data, test.payload[1:]...)
+
+		_, err := ParseNBT(data, false)
+		if err == nil {
+			t.Errorf("%s: expected an error", test.name)
+		} else if test.cause != nil && !errors.Is(err, test.cause) {
+			t.Errorf("%s: expected %v, got %v", test.name, test.cause, err)
+		}
+		if _, err := NewDecoder(bytes.NewReader(data), false).Decode(); err == nil {
+			t.Errorf("%s: expected an error from the decoder", test.name)
+		}
+	}
+}
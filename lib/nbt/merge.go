@@ -0,0 +1,118 @@
+package nbt
+
+import "fmt"
+
+// ListMerge decides what Merge does with a list present in both compounds
+type ListMerge int
+
+const (
+	// ListReplace replaces the list with the source's, as /data merge does
+	ListReplace ListMerge = iota
+	// ListAppend appends the source's elements to the list
+	ListAppend
+	// ListUnique appends the source's elements that the list does not already hold
+	ListUnique
+)
+
+// MergeStrategy tunes Merge. The zero value merges as Minecraft's /data merge command.
+type MergeStrategy struct {
+	Lists ListMerge
+	// ErrorOnTypeConflict fails instead of replacing a tag with a source tag of another type,
+	// or appending to a list elements of another type
+	ErrorOnTypeConflict bool
+}
+
+// Merge copies the children of src into dst, merging compounds present in both recursively.
+// Other tags from src replace the ones in dst, except lists with the ListAppend and ListUnique strategies.
+// A type conflict under ErrorOnTypeConflict fails with an error wrapping ErrIncompatibleType
+// that gives its path, and then dst is left unchanged.
+func Merge(dst, src *TagCompound, strategy MergeStrategy) error {
+	if strategy.ErrorOnTypeConflict {
+		// find conflicts before changing anything
+		check := &merger{strategy: strategy, dryRun: true}
+		if err := check.mergeCompounds(dst, src); err != nil {
+			return err
+		}
+	}
+	return (&merger{strategy: strategy}).mergeCompounds(dst, src)
+}
+
+type merger struct {
+	strategy MergeStrategy
+	// dryRun only looks for conflicts
+	dryRun bool
+	path   []pathSegment
+}
+
+func (m *merger) conflict(format string, args ...any) error {
+	return fmt.Errorf("cannot merge %s at %s: %w", fmt.Sprintf(format, args...), formatPath(m.path), ErrIncompatibleType)
+}
+
+func (m *merger) mergeCompounds(dst, src *TagCompound) error {
+	for name, child := range src.All() {
+		m.path = append(m.path, pathSegment{name, -1})
+		if err := m.mergeChild(dst, dst.Get(name), child); err != nil {
+			return err
+		}
+		m.path = m.path[:len(m.path)-1]
+	}
+	return nil
+}
+
+// mergeChild merges the source child into the existing one of dst, which may be nil
+func (m *merger) mergeChild(dst *TagCompound, existing, child NBTTag) error {
+	if existing != nil && existing.Type() != child.Type() && m.strategy.ErrorOnTypeConflict {
+		return m.conflict("%s into %s", TagName[child.Type()], TagName[existing.Type()])
+	}
+	switch child := child.(type) {
+	case *TagCompound:
+		if existing, ok := existing.(*TagCompound); ok {
+			return m.mergeCompounds(existing, child)
+		}
+	case *TagList:
+		if existing, ok := existing.(*TagList); ok && m.strategy.Lists != ListReplace {
+			return m.mergeLists(existing, child)
+		}
+	}
+	if !m.dryRun {
+		dst.Set(Clone(child))
+	}
+	return nil
+}
+
+func (m *merger) mergeLists(dst, src *TagList) error {
+	if len(dst.Value) > 0 && len(src.Value) > 0 && dst.ElementType != src.ElementType {
+		if m.strategy.ErrorOnTypeConflict {
+			return m.conflict("a list of %s into a list of %s", TagName[src.ElementType], TagName[dst.ElementType])
+		}
+		if !m.dryRun {
+			dst.ElementType, dst.Value = src.ElementType, nil
+			for _, item := range src.Value {
+				dst.Value = append(dst.Value, Clone(item))
+			}
+		}
+		return nil
+	}
+	if m.dryRun {
+		return nil
+	}
+	for _, item := range src.Value {
+		if m.strategy.Lists == ListUnique && containsValue(dst.Value, item) {
+			continue
+		}
+		if err := dst.Append(Clone(item)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// containsValue reports whether items holds a tag equal to item
+func containsValue(items []NBTTag, item NBTTag) bool {
+	for _, other := range items {
+		if equalValues(other, item, EqualOptions{}) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,80 @@
+package nbt
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestMerge(t *testing.T) {
+	const dst = `{id: "minecraft:zombie", Health: 20f, Tags: ["a", "b"], Equipment: {head: {id: "helmet", Count: 1b}, feet: {id: "boots"}}}`
+	const src = `{Health: 40d, Tags: ["b", "c"], Equipment: {head: {Count: 2b}, hand: {id: "sword"}}, NoAI: 1b}`
+	tests := []struct {
+		lists    ListMerge
+		expected string
+	}{
+		{ListReplace, `{id:"minecraft:zombie",Health:40d,Tags:["b","c"],` +
+			`Equipment:{head:{id:"helmet",Count:2b},feet:{id:"boots"},hand:{id:"sword"}},NoAI:1b}`},
+		{ListAppend, `{id:"minecraft:zombie",Health:40d,Tags:["a","b","b","c"],` +
+			`Equipment:{head:{id:"helmet",Count:2b},feet:{id:"boots"},hand:{id:"sword"}},NoAI:1b}`},
+		{ListUnique, `{id:"minecraft:zombie",Health:40d,Tags:["a","b","c"],` +
+			`Equipment:{head:{id:"helmet",Count:2b},feet:{id:"boots"},hand:{id:"sword"}},NoAI:1b}`},
+	}
+	for _, test := range tests {
+		target := mustParseSNBT(t, dst).(*TagCompound)
+		source := mustParseSNBT(t, src).(*TagCompound)
+		if err := Merge(target, source, MergeStrategy{Lists: test.lists}); err != nil {
+			t.Fatalf("Failed to merge: %v", err)
+		}
+		if FormatSNBT(target) != test.expected {
+			t.Errorf("Expected %s\ngot      %s", test.expected, FormatSNBT(target))
+		}
+		if FormatSNBT(source) != FormatSNBT(mustParseSNBT(t, src)) {
+			t.Errorf("Expected the source to be unchanged, got %s", FormatSNBT(source))
+		}
+	}
+}
+
+func TestMergeCopiesSource(t *testing.T) {
+	target := NewCompound("")
+	source := mustParseSNBT(t, `{a: {b: [I; 1]}}`).(*TagCompound)
+	if err := Merge(target, source, MergeStrategy{}); err != nil {
+		t.Fatalf("Failed to merge: %v", err)
+	}
+	a, _ := source.GetCompound("a")
+	b, _ := a.GetIntArray("b")
+	b[0] = 2
+	if FormatSNBT(target) != `{a:{b:[I;1]}}` {
+		t.Errorf("Expected the merged tags to be copies, got %s", FormatSNBT(target))
+	}
+}
+
+func TestMergeTypeConflicts(t *testing.T) {
+	tests := []struct {
+		dst, src string
+		lists    ListMerge
+		replaced string
+		path     string
+	}{
+		{`{a: {b: 1, c: 1}}`, `{a: {b: 2, c: "x"}}`, ListReplace, `{a:{b:2,c:"x"}}`, "a.c"},
+		{`{a: [1], b: 1}`, `{b: 2, a: ["x"]}`, ListAppend, `{a:["x"],b:2}`, "a"},
+	}
+	for _, test := range tests {
+		target := mustParseSNBT(t, test.dst).(*TagCompound)
+		err := Merge(target, mustParseSNBT(t, test.src).(*TagCompound), MergeStrategy{Lists: test.lists, ErrorOnTypeConflict: true})
+		if !errors.Is(err, ErrIncompatibleType) || !strings.Contains(err.Error(), "at "+test.path+":") {
+			t.Errorf("Expected a type conflict at %s, got %v", test.path, err)
+		}
+		if FormatSNBT(target) != FormatSNBT(mustParseSNBT(t, test.dst)) {
+			t.Errorf("Expected a failed merge to leave the target unchanged, got %s", FormatSNBT(target))
+		}
+
+		// by default the source wins
+		if err := Merge(target, mustParseSNBT(t, test.src).(*TagCompound), MergeStrategy{Lists: test.lists}); err != nil {
+			t.Fatalf("Failed to merge: %v", err)
+		}
+		if FormatSNBT(target) != test.replaced {
+			t.Errorf("Expected %s, got %s", test.replaced, FormatSNBT(target))
+		}
+	}
+}
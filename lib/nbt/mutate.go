@@ -0,0 +1,256 @@
+package nbt
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"strconv"
+)
+
+// ErrIncompatibleType means a tag cannot be stored or converted where it was asked to
+var ErrIncompatibleType = errors.New("incompatible tag type")
+
+// Append adds items to the end of the list, see Insert
+func (t *TagList) Append(items ...NBTTag) error {
+	return t.Insert(len(t.Value), items...)
+}
+
+// Insert adds items before the element at index, which may be len(t.Value) to append.
+// An empty list takes the type of the first item, otherwise every item must be of the list's ElementType.
+// The names of the items are cleared, since list elements are nameless.
+// Like slices.Insert, it panics if index is out of range.
+func (t *TagList) Insert(index int, items ...NBTTag) error {
+	if len(items) == 0 {
+		return nil
+	}
+	elementType := t.ElementType
+	if len(t.Value) == 0 {
+		elementType = items[0].Type()
+	}
+	for i, item := range items {
+		if item.Type() != elementType || item.Type() == BTagEnd {
+			return fmt.Errorf("cannot add %s as item %d of a list of %s: %w",
+				TagName[item.Type()], i, TagName[elementType], ErrIncompatibleType)
+		}
+	}
+	for _, item := range items {
+		item.SetName("")
+	}
+	t.ElementType = elementType
+	t.Value = slices.Insert(t.Value, index, items...)
+	return nil
+}
+
+// Remove takes the element at index out of the list and returns it, so it can be moved elsewhere.
+// It panics if index is out of range.
+func (t *TagList) Remove(index int) NBTTag {
+	item := t.Value[index]
+	t.Value = slices.Delete(t.Value, index, index+1)
+	return item
+}
+
+// Retype returns a new tag of tagType with the same name, holding the value of tag converted:
+//
+//   - numbers convert to each other like casts in Java: integers wrap around, and floating point
+//     numbers are truncated toward zero and clamped to the int range, or the long range for longs,
+//     with NaN becoming 0; bytes and shorts then wrap around from the int
+//   - numbers convert to strings, and strings that parse as a number convert back
+//   - byte, int and long arrays convert to each other, and to and from lists of their element type
+//
+// A tag that already is of tagType is returned as it is.
+// Other conversions fail with an error wrapping ErrIncompatibleType.
+func Retype(tag NBTTag, tagType tagTypeByte) (NBTTag, error) {
+	if tag.Type() == tagType {
+		return tag, nil
+	}
+	name := tag.Name()
+	if i, f, isFloat, ok := numericValue(tag); ok {
+		if isFloat && tagType == BTagString {
+			bitSize := 64
+			if tag.Type() == BTagFloat {
+				bitSize = 32
+			}
+			return NewString(name, strconv.FormatFloat(f, 'g', -1, bitSize)), nil
+		}
+		if tagType == BTagString {
+			return NewString(name, strconv.FormatInt(i, 10)), nil
+		}
+		if converted := newNumeric(name, tagType, i, f, isFloat); converted != nil {
+			return converted, nil
+		}
+	}
+	if s, ok := tag.(*TagString); ok {
+		if converted := parseNumeric(name, tagType, s.Value); converted != nil {
+			return converted, nil
+		}
+	}
+	if values, ok := integerValues(tag); ok {
+		if converted := newIntegerArray(name, tagType, values); converted != nil {
+			return converted, nil
+		}
+		if elementType := arrayElementType(tag.Type()); tagType == BTagList && elementType != BTagEnd {
+			list := NewList(name, elementType)
+			list.Value = make([]NBTTag, len(values))
+			for i, value := range values {
+				list.Value[i] = newNumeric("", elementType, value, 0, false)
+			}
+			return list, nil
+		}
+	}
+	return nil, incompatibleConversion(tag, tagType)
+}
+
+func incompatibleConversion(tag NBTTag, tagType tagTypeByte) error {
+	return fmt.Errorf("cannot convert %s to %s: %w", TagName[tag.Type()], TagName[tagType], ErrIncompatibleType)
+}
+
+// numericValue returns the value of a number tag, as an integer or, if isFloat, as a floating point number
+func numericValue(tag NBTTag) (i int64, f float64, isFloat bool, ok bool) {
+	switch t := tag.(type) {
+	case *TagByte:
+		return int64(int8(t.Value)), 0, false, true
+	case *TagShort:
+		return int64(t.Value), 0, false, true
+	case *TagInt:
+		return int64(t.Value), 0, false, true
+	case *TagLong:
+		return t.Value, 0, false, true
+	case *TagFloat:
+		return 0, float64(t.Value), true, true
+	case *TagDouble:
+		return 0, t.Value, true, true
+	}
+	return 0, 0, false, false
+}
+
+// newNumeric returns a number tag of tagType holding i, or f if isFloat, or nil if tagType is not a number
+func newNumeric(name string, tagType tagTypeByte, i int64, f float64, isFloat bool) NBTTag {
+	if isFloat {
+		switch tagType {
+		case BTagFloat:
+			return NewFloat(name, float32(f))
+		case BTagDouble:
+			return NewDouble(name, f)
+		case BTagByte, BTagShort, BTagInt:
+			// Java narrows to int first, (byte) 300.5 is 44
+			i = clampFloat(f, math.MinInt32, math.MaxInt32)
+		case BTagLong:
+			i = clampFloat(f, math.MinInt64, math.MaxInt64)
+		}
+	}
+	switch tagType {
+	case BTagByte:
+		return NewByte(name, byte(i))
+	case BTagShort:
+		return NewShort(name, int16(i))
+	case BTagInt:
+		return NewInt(name, int32(i))
+	case BTagLong:
+		return NewLong(name, i)
+	case BTagFloat:
+		return NewFloat(name, float32(i))
+	case BTagDouble:
+		return NewDouble(name, float64(i))
+	}
+	return nil
+}
+
+// clampFloat truncates f toward zero within [min, max], with NaN becoming 0
+func clampFloat(f float64, min, max int64) int64 {
+	switch {
+	case math.IsNaN(f):
+		return 0
+	case f <= float64(min):
+		return min
+	case f >= float64(max):
+		return max
+	}
+	return int64(f)
+}
+
+// parseNumeric returns a number tag of tagType parsed from s, or nil if s does not parse as one
+func parseNumeric(name string, tagType tagTypeByte, s string) NBTTag {
+	switch tagType {
+	case BTagByte, BTagShort, BTagInt, BTagLong:
+		bitSize := TagPayloadLength[tagType] * 8
+		i, err := strconv.ParseInt(s, 10, bitSize)
+		if err != nil {
+			return nil
+		}
+		return newNumeric(name, tagType, i, 0, false)
+	case BTagFloat, BTagDouble:
+		bitSize := TagPayloadLength[tagType] * 8
+		f, err := strconv.ParseFloat(s, bitSize)
+		if err != nil {
+			return nil
+		}
+		return newNumeric(name, tagType, 0, f, true)
+	}
+	return nil
+}
+
+// integerValues returns the elements of an integer array or of a list of integers
+func integerValues(tag NBTTag) ([]int64, bool) {
+	switch t := tag.(type) {
+	case *TagByteArray:
+		values := make([]int64, len(t.Value))
+		for i, value := range t.Value {
+			values[i] = int64(int8(value))
+		}
+		return values, true
+	case *TagIntArray:
+		values := make([]int64, len(t.Value))
+		for i, value := range t.Value {
+			values[i] = int64(value)
+		}
+		return values, true
+	case *TagLongArray:
+		return slices.Clone(t.Value), true
+	case *TagList:
+		if arrayType(t.ElementType) == BTagEnd && len(t.Value) > 0 {
+			return nil, false
+		}
+		values := make([]int64, len(t.Value))
+		for i, item := range t.Value {
+			values[i], _, _, _ = numericValue(item)
+		}
+		return values, true
+	}
+	return nil, false
+}
+
+// newIntegerArray returns an array tag of tagType holding values, or nil if tagType is not an array
+func newIntegerArray(name string, tagType tagTypeByte, values []int64) NBTTag {
+	switch tagType {
+	case BTagByteArray:
+		converted := make([]byte, len(values))
+		for i, value := range values {
+			converted[i] = byte(value)
+		}
+		return NewByteArray(name, converted)
+	case BTagIntArray:
+		converted := make([]int32, len(values))
+		for i, value := range values {
+			converted[i] = int32(value)
+		}
+		return NewIntArray(name, converted)
+	case BTagLongArray:
+		return NewLongArray(name, values)
+	}
+	return nil
+}
+
+// arrayType returns the array type storing elementType numbers, the inverse of arrayElementType
+func arrayType(elementType tagTypeByte) tagTypeByte {
+	switch elementType {
+	case BTagByte:
+		return BTagByteArray
+	case BTagInt:
+		return BTagIntArray
+	case BTagLong:
+		return BTagLongArray
+	default:
+		return BTagEnd
+	}
+}
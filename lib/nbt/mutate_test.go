@@ -0,0 +1,138 @@
+package nbt
+
+import (
+	"errors"
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestMoveSubtree(t *testing.T) {
+	item := NewCompound("", NewString("id", "minecraft:stone"))
+	inventory := NewList("Inventory", BTagCompound, item)
+	root := NewCompound("", inventory)
+
+	// move the item out of the list and under its own key
+	moved := inventory.Remove(0)
+	moved.SetName("HandItem")
+	root.Set(moved)
+
+	serialized, err := SerializeNBT(root, false)
+	if err != nil {
+		t.Fatalf("Failed to serialize edited tree: %v", err)
+	}
+	parsed, parseErr := ParseNBT(serialized, false)
+	if parseErr != nil {
+		t.Fatalf("Failed to parse edited tree: %v", parseErr)
+	}
+	children := parsed.(*TagCompound).Value
+	if len(children[0].(*TagList).Value) != 0 {
+		t.Errorf("Expected an empty Inventory, got %v", children[0])
+	}
+	if children[1].Name() != "HandItem" || children[1].Type() != BTagCompound {
+		t.Errorf("Expected the moved compound as HandItem, got %s %q", TagName[children[1].Type()], children[1].Name())
+	}
+
+	// and back into the list, which drops the name again
+	if err := inventory.Append(moved); err != nil {
+		t.Fatalf("Failed to append to list: %v", err)
+	}
+	if moved.Name() != "" {
+		t.Errorf("Expected list elements to be nameless, got %q", moved.Name())
+	}
+}
+
+func TestListInsertChecksType(t *testing.T) {
+	list := NewList("values", BTagEnd)
+	if err := list.Append(NewInt("", 1), NewInt("", 3)); err != nil {
+		t.Fatalf("Failed to append to an empty list: %v", err)
+	}
+	if list.ElementType != BTagInt {
+		t.Errorf("Expected the empty list to take TAG_Int, got %s", TagName[list.ElementType])
+	}
+	if err := list.Insert(1, NewInt("", 2)); err != nil {
+		t.Fatalf("Failed to insert into list: %v", err)
+	}
+	for i, item := range list.Value {
+		if item.(*TagInt).Value != int32(i+1) {
+			t.Errorf("Expected element %d to be %d, got %d", i, i+1, item.(*TagInt).Value)
+		}
+	}
+	if err := list.Append(NewLong("", 4)); !errors.Is(err, ErrIncompatibleType) {
+		t.Errorf("Expected ErrIncompatibleType for a TAG_Long in a TAG_Int list, got %v", err)
+	}
+}
+
+func TestRetype(t *testing.T) {
+	tests := []struct {
+		tag      NBTTag
+		tagType  tagTypeByte
+		expected any
+	}{
+		{NewInt("n", 300), BTagByte, byte(44)},
+		{NewByte("n", 0xFF), BTagLong, int64(-1)},
+		{NewDouble("n", -2.7), BTagInt, int32(-2)},
+		{NewDouble("n", 1e20), BTagInt, int32(math.MaxInt32)},
+		{NewDouble("n", 1e20), BTagLong, int64(math.MaxInt64)},
+		{NewFloat("n", float32(math.NaN())), BTagShort, int16(0)},
+		{NewDouble("n", 300.5), BTagByte, byte(44)},
+		{NewDouble("n", 1e20), BTagShort, int16(-1)},
+		{NewFloat("n", -1e10), BTagByte, byte(0)},
+		{NewFloat("n", 0.1), BTagString, "0.1"},
+		{NewString("n", "42"), BTagShort, int16(42)},
+		{NewByteArray("n", []byte{1, 0xFF}), BTagIntArray, []int32{1, -1}},
+	}
+	for _, test := range tests {
+		converted, err := Retype(test.tag, test.tagType)
+		if err != nil {
+			t.Errorf("Failed to convert %s to %s: %v", TagName[test.tag.Type()], TagName[test.tagType], err)
+			continue
+		}
+		if converted.Type() != test.tagType || converted.Name() != "n" {
+			t.Errorf("Expected %s n, got %s %q", TagName[test.tagType], TagName[converted.Type()], converted.Name())
+			continue
+		}
+		var value any
+		switch c := converted.(type) {
+		case *TagByte:
+			value = c.Value
+		case *TagShort:
+			value = c.Value
+		case *TagInt:
+			value = c.Value
+		case *TagLong:
+			value = c.Value
+		case *TagString:
+			value = c.Value
+		case *TagIntArray:
+			value = c.Value
+		}
+		if !reflect.DeepEqual(value, test.expected) {
+			t.Errorf("Expected %s to convert to %v, got %v", TagName[test.tag.Type()], test.expected, value)
+		}
+	}
+}
+
+func TestRetypeArrayAndList(t *testing.T) {
+	list, err := Retype(NewLongArray("a", []int64{5, 6}), BTagList)
+	if err != nil {
+		t.Fatalf("Failed to convert TAG_Long_Array to a list: %v", err)
+	}
+	if list.(*TagList).ElementType != BTagLong || len(list.(*TagList).Value) != 2 {
+		t.Fatalf("Expected a list of 2 TAG_Long, got %v", list)
+	}
+	array, err := Retype(list, BTagLongArray)
+	if err != nil {
+		t.Fatalf("Failed to convert list back to TAG_Long_Array: %v", err)
+	}
+	if values := array.(*TagLongArray).Value; values[0] != 5 || values[1] != 6 {
+		t.Errorf("Expected [5 6], got %v", values)
+	}
+
+	if _, err := Retype(NewString("s", "text"), BTagInt); !errors.Is(err, ErrIncompatibleType) {
+		t.Errorf("Expected ErrIncompatibleType for a non-numeric string, got %v", err)
+	}
+	if _, err := Retype(NewList("l", BTagString, NewString("", "x")), BTagIntArray); !errors.Is(err, ErrIncompatibleType) {
+		t.Errorf("Expected ErrIncompatibleType for a list of strings, got %v", err)
+	}
+}
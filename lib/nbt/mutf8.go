@@ -0,0 +1,123 @@
+package nbt
+
+import (
+	"bytes"
+	"slices"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// Java Edition stores tag names and TAG_String payloads in Java's Modified UTF-8:
+// U+0000 is written as the two bytes 0xC0 0x80, and characters outside the BMP are written
+// as a UTF-16 surrogate pair with each half encoded as its own 3 byte sequence.
+// Bedrock Edition uses standard UTF-8. Earlier versions of this library wrote standard UTF-8
+// for Java too, so the 4 byte sequences it used for characters outside the BMP are still read.
+
+// isPlainASCII reports whether b is encoded identically in UTF-8 and Modified UTF-8
+func isPlainASCII[T string | []byte](b T) bool {
+	for i := 0; i < len(b); i++ {
+		if b[i] == 0 || b[i] >= utf8.RuneSelf {
+			return false
+		}
+	}
+	return true
+}
+
+// decodeMUTF8 converts Modified UTF-8 bytes to a Go string, accepting well-formed
+// standard UTF-8 4 byte sequences as well. Unpaired surrogates become U+FFFD,
+// as they have no UTF-8 representation.
+func decodeMUTF8(b []byte) (string, error) {
+	if isPlainASCII(b) {
+		return string(b), nil
+	}
+	units := make([]uint16, 0, len(b))
+	for i := 0; i < len(b); {
+		c := b[i]
+		switch {
+		case c < 0x80:
+			units = append(units, uint16(c))
+			i++
+		case c&0xE0 == 0xC0:
+			if i+1 >= len(b) || b[i+1]&0xC0 != 0x80 {
+				return "", ErrInvalidMUTF8
+			}
+			units = append(units, uint16(c&0x1F)<<6|uint16(b[i+1]&0x3F))
+			i += 2
+		case c&0xF0 == 0xE0:
+			if i+2 >= len(b) || b[i+1]&0xC0 != 0x80 || b[i+2]&0xC0 != 0x80 {
+				return "", ErrInvalidMUTF8
+			}
+			units = append(units, uint16(c&0x0F)<<12|uint16(b[i+1]&0x3F)<<6|uint16(b[i+2]&0x3F))
+			i += 3
+		case c&0xF8 == 0xF0:
+			r, size := utf8.DecodeRune(b[i:])
+			if size != 4 {
+				return "", ErrInvalidMUTF8
+			}
+			high, low := utf16.EncodeRune(r)
+			units = append(units, uint16(high), uint16(low))
+			i += 4
+		default:
+			return "", ErrInvalidMUTF8
+		}
+	}
+	return string(utf16.Decode(units)), nil
+}
+
+// replacedSurrogates reports whether decodeMUTF8 had to replace unpaired surrogates in b to produce s
+func replacedSurrogates(b []byte, s string) bool {
+	return strings.Count(s, string(utf8.RuneError)) > bytes.Count(b, []byte(string(utf8.RuneError)))
+}
+
+// hasFourByteSequences reports whether b holds standard UTF-8 4 byte sequences,
+// which decodeMUTF8 accepts but appendMUTF8 writes back as surrogate pairs
+func hasFourByteSequences(b []byte) bool {
+	return slices.ContainsFunc(b, func(c byte) bool { return c&0xF8 == 0xF0 })
+}
+
+// appendMUTF8 appends the Modified UTF-8 encoding of s to dst.
+// Invalid UTF-8 in s is encoded as U+FFFD.
+func appendMUTF8(dst []byte, s string) []byte {
+	for _, r := range s {
+		switch {
+		case r == 0:
+			dst = append(dst, 0xC0, 0x80)
+		case r < 0x80:
+			dst = append(dst, byte(r))
+		case r < 0x800:
+			dst = append(dst, 0xC0|byte(r>>6), 0x80|byte(r&0x3F))
+		case r < 0x10000:
+			dst = appendMUTF8Unit(dst, uint16(r))
+		default:
+			high, low := utf16.EncodeRune(r)
+			dst = appendMUTF8Unit(dst, uint16(high))
+			dst = appendMUTF8Unit(dst, uint16(low))
+		}
+	}
+	return dst
+}
+
+func appendMUTF8Unit(dst []byte, unit uint16) []byte {
+	return append(dst, 0xE0|byte(unit>>12), 0x80|byte(unit>>6&0x3F), 0x80|byte(unit&0x3F))
+}
+
+// mutf8Length returns the number of bytes appendMUTF8 produces for s
+func mutf8Length(s string) int {
+	length := 0
+	for _, r := range s {
+		switch {
+		case r == 0:
+			length += 2
+		case r < 0x80:
+			length++
+		case r < 0x800:
+			length += 2
+		case r < 0x10000:
+			length += 3
+		default:
+			length += 6
+		}
+	}
+	return length
+}
@@ -0,0 +1,97 @@
+package nbt
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestMUTF8Encoding(t *testing.T) {
+	cases := []struct {
+		value   string
+		encoded []byte
+	}{
+		{"plain", []byte("plain")},
+		{"a\x00b", []byte{'a', 0xC0, 0x80, 'b'}},
+		{"é", []byte{0xC3, 0xA9}},
+		{"€", []byte{0xE2, 0x82, 0xAC}},
+		// U+1F600 is the surrogate pair D83D DE00, each written as 3 bytes
+		{"\U0001F600", []byte{0xED, 0xA0, 0xBD, 0xED, 0xB8, 0x80}},
+	}
+	for _, c := range cases {
+		encoded := appendMUTF8(nil, c.value)
+		if !bytes.Equal(encoded, c.encoded) {
+			t.Errorf("%q: expected encoding % x, got % x", c.value, c.encoded, encoded)
+		}
+		if mutf8Length(c.value) != len(c.encoded) {
+			t.Errorf("%q: expected length %d, got %d", c.value, len(c.encoded), mutf8Length(c.value))
+		}
+		decoded, err := decodeMUTF8(c.encoded)
+		if err != nil {
+			t.Errorf("%q: failed to decode: %v", c.value, err)
+		}
+		if decoded != c.value {
+			t.Errorf("Expected decoded %q, got %q", c.value, decoded)
+		}
+	}
+}
+
+func TestMUTF8FourByteSequence(t *testing.T) {
+	decoded, err := decodeMUTF8([]byte{'a', 0xF0, 0x9F, 0x98, 0x80})
+	if err != nil || decoded != "a\U0001F600" {
+		t.Errorf("Expected a standard UTF-8 4 byte sequence to be read, got %q: %v", decoded, err)
+	}
+	for _, b := range [][]byte{{0xF0, 0x9F, 0x98}, {0xF4, 0x90, 0x80, 0x80}, {'a', 0xC0}} {
+		if _, err := decodeMUTF8(b); err == nil {
+			t.Errorf("Expected an error for % x", b)
+		}
+	}
+}
+
+func TestStringTagJavaAndBedrockEncoding(t *testing.T) {
+	text := "sign\x00\U0001F600"
+	tag := &TagCompound{
+		baseTag: baseTag{tagType: BTagCompound, name: "\U0001F4D6"},
+		Value: []NBTTag{
+			&TagString{baseTag: baseTag{tagType: BTagString, name: "Text1"}, Value: text},
+			&TagEnd{baseTag: baseTag{tagType: BTagEnd}},
+		},
+	}
+
+	javaBytes, err := SerializeNBT(tag, false)
+	if err != nil {
+		t.Fatalf("Failed to serialize Java NBT: %v", err)
+	}
+	// name: 6 byte surrogate pair; value: "sign" + 0xC0 0x80 + 6 byte surrogate pair
+	if !bytes.Contains(javaBytes, []byte{0x00, 0x0C, 's', 'i', 'g', 'n', 0xC0, 0x80, 0xED, 0xA0, 0xBD, 0xED, 0xB8, 0x80}) {
+		t.Errorf("Java string not written as Modified UTF-8: % x", javaBytes)
+	}
+	if len(javaBytes) != GetTagFullSize(tag) {
+		t.Errorf("Expected GetTagFullSize %d to match serialized length %d", GetTagFullSize(tag), len(javaBytes))
+	}
+
+	bedrockBytes, err := SerializeNBT(tag, true)
+	if err != nil {
+		t.Fatalf("Failed to serialize Bedrock NBT: %v", err)
+	}
+	if !bytes.Contains(bedrockBytes, []byte{0x09, 0x00, 's', 'i', 'g', 'n', 0x00, 0xF0, 0x9F, 0x98, 0x80}) {
+		t.Errorf("Bedrock string not written as UTF-8: % x", bedrockBytes)
+	}
+
+	for _, isBedrock := range []bool{false, true} {
+		data := javaBytes
+		if isBedrock {
+			data = bedrockBytes
+		}
+		parsed, parseErr := ParseNBT(data, isBedrock)
+		if parseErr != nil {
+			t.Fatalf("Failed to parse (bedrock=%v): %v", isBedrock, parseErr)
+		}
+		if parsed.Name() != "\U0001F4D6" {
+			t.Errorf("Expected root name %q, got %q", "\U0001F4D6", parsed.Name())
+		}
+		value := parsed.(*TagCompound).Value[0].(*TagString).Value
+		if value != text {
+			t.Errorf("Expected string %q, got %q (bedrock=%v)", text, value, isBedrock)
+		}
+	}
+}
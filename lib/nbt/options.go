@@ -0,0 +1,203 @@
+package nbt
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"fmt"
+	"goNbt/lib"
+	"io"
+)
+
+// Compression selects how the NBT bytes are compressed. The names match lib.NewZipWriter.
+type Compression string
+
+const (
+	CompressionNone Compression = ""
+	CompressionGzip Compression = "gzip"
+	CompressionZlib Compression = "zlib"
+	// CompressionAuto detects gzip and zlib by their magic bytes and passes anything else through.
+	// It is only valid for parsing.
+	CompressionAuto Compression = "auto"
+)
+
+// TrailingDataPolicy decides what happens to bytes left after the root tag
+type TrailingDataPolicy int
+
+const (
+	// Fail with an error wrapping ErrTrailingData
+	TrailingDataError TrailingDataPolicy = iota
+	// Report the trailing bytes to the warning handler and return the root tag
+	TrailingDataWarn
+	// Return the root tag and ignore the trailing bytes
+	TrailingDataIgnore
+)
+
+// ParseOptions configures how NBT is read. The zero value reads uncompressed Java NBT.
+type ParseOptions struct {
+	Encoding Encoding
+	// NamelessRoot selects the network format used by the Java protocol since 1.20.2,
+	// where the root tag's type ID is followed directly by its payload, without a name
+	NamelessRoot   bool
+	StringEncoding StringEncoding
+	// Limits bounds the resources used by parsing, the zero value means DefaultLimits
+	Limits Limits
+	// TrailingData only applies to single-tag parsing, a Decoder reads on to the next root tag instead
+	TrailingData TrailingDataPolicy
+	Compression  Compression
+	// Warn receives problems that do not stop parsing, nil to ignore them
+	Warn WarningHandler
+	// Lenient salvages what it can from corrupt input instead of failing at the first error.
+	// Parsing then returns the partial tree together with a ParseErrors listing every error,
+	// and compounds and lists the input broke off in are marked Truncated. When decompression fails,
+	// what was decompressed before is parsed, and the error and a Truncated root are added.
+	// The PullParser and ParseNBTSequence do not support it.
+	Lenient bool
+	// AllowScalarRoot accepts any tag but TAG_End as a root, instead of only Compound and List tags
+	AllowScalarRoot bool
+	// CopyByteArrays gives parsed byte arrays memory of their own. Otherwise, when parsing
+	// uncompressed data from a slice, they alias it, so changing one changes the other.
+	CopyByteArrays bool
+}
+
+// SerializeOptions configures how NBT is written. The zero value writes uncompressed Java NBT.
+type SerializeOptions struct {
+	Encoding Encoding
+	// NamelessRoot writes the root without a name, see ParseOptions.NamelessRoot
+	NamelessRoot   bool
+	StringEncoding StringEncoding
+	Compression    Compression
+}
+
+// ParseNBTWithOptions parses a single root Compound or List tag as configured by opts.
+// Parse failures are returned as a TagParseError.
+func ParseNBTWithOptions(data []byte, opts ParseOptions) (NBTTag, error) {
+	data, err := decompressData(data, opts)
+	if err != nil && (!opts.Lenient || len(data) == 0) {
+		return nil, err
+	}
+	tag, errs := readRootData(data, opts)
+	if err != nil {
+		// a lenient parse salvages what could be decompressed before the error,
+		// but nothing in it can be trusted to be complete
+		rootType := BTagEnd
+		if tag != nil {
+			rootType = tag.Type()
+			markTruncated(tag)
+		}
+		errs = append(errs, newParseValueError("failed to decompress", int64(len(data)), rootType, err))
+	}
+	if len(errs) == 0 {
+		return tag, nil
+	}
+	if !opts.Lenient {
+		return nil, errs[0]
+	}
+	return tag, ParseErrors(errs)
+}
+
+// SerializeNBTWithOptions serializes a root tag as configured by opts
+func SerializeNBTWithOptions(tag NBTTag, opts SerializeOptions) ([]byte, error) {
+	var buf bytes.Buffer
+	encoder, err := NewEncoderWithOptions(&buf, opts)
+	if err != nil {
+		return nil, err
+	}
+	if err := encoder.Encode(tag); err != nil {
+		return nil, err
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// NewDecoderWithOptions returns a Decoder reading from r as configured by opts.
+// With compression, the gzip or zlib header is read right away.
+func NewDecoderWithOptions(r io.Reader, opts ParseOptions) (*Decoder, error) {
+	r, err := newDecompressor(r, opts.Compression)
+	if err != nil {
+		return nil, err
+	}
+	src := newReaderSource(r)
+	return &Decoder{
+		src:          src,
+		parser:       newParser(src, opts),
+		namelessRoot: opts.NamelessRoot,
+	}, nil
+}
+
+// NewPullParserWithOptions returns a PullParser reading from r as configured by opts
+func NewPullParserWithOptions(r io.Reader, opts ParseOptions) (*PullParser, error) {
+	r, err := newDecompressor(r, opts.Compression)
+	if err != nil {
+		return nil, err
+	}
+	p := &PullParser{
+		parser:       newParser(newReaderSource(r), opts),
+		namelessRoot: opts.NamelessRoot,
+	}
+	p.parser.lenient = false
+	return p, nil
+}
+
+// NewEncoderWithOptions returns an Encoder writing to w as configured by opts.
+// With compression, Close must be called after the last tag to finish the compressed stream.
+func NewEncoderWithOptions(w io.Writer, opts SerializeOptions) (*Encoder, error) {
+	var compressor io.WriteCloser
+	switch opts.Compression {
+	case CompressionNone:
+	case CompressionGzip, CompressionZlib:
+		compressor = lib.NewZipWriter(w, string(opts.Compression))
+		w = compressor
+	default:
+		return nil, fmt.Errorf("unsupported compression for serializing: %q", opts.Compression)
+	}
+	e := newEncoder(w, opts.Encoding)
+	e.strings = opts.StringEncoding
+	e.namelessRoot = opts.NamelessRoot
+	e.compressor = compressor
+	return e, nil
+}
+
+// decompressData returns data decompressed as selected by opts.Compression.
+// After an error it also returns what could be decompressed before it.
+func decompressData(data []byte, opts ParseOptions) ([]byte, error) {
+	if opts.Compression == CompressionNone {
+		return data, nil
+	}
+	r, err := newDecompressor(bytes.NewReader(data), opts.Compression)
+	if err != nil {
+		return nil, err
+	}
+	return io.ReadAll(r)
+}
+
+// markTruncated marks a compound or list as broken off
+func markTruncated(tag NBTTag) {
+	switch t := tag.(type) {
+	case *TagCompound:
+		t.Truncated = true
+	case *TagList:
+		t.Truncated = true
+	}
+}
+
+func newDecompressor(r io.Reader, compression Compression) (io.Reader, error) {
+	switch compression {
+	case CompressionNone:
+		return r, nil
+	case CompressionAuto:
+		return lib.NewUnzipReader(r)
+	case CompressionGzip:
+		gzipReader, err := gzip.NewReader(r)
+		if err != nil {
+			return nil, err
+		}
+		return gzipReader, nil
+	case CompressionZlib:
+		return zlib.NewReader(r)
+	default:
+		return nil, fmt.Errorf("unsupported compression: %q", compression)
+	}
+}
@@ -0,0 +1,93 @@
+package nbt
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func TestSerializeAndParseWithCompression(t *testing.T) {
+	expected, parseErr := ParseNBT(decoderTestData(), false)
+	if parseErr != nil {
+		t.Fatalf("Failed to parse NBT: %v", parseErr)
+	}
+	for _, compression := range []Compression{CompressionNone, CompressionGzip, CompressionZlib} {
+		data, err := SerializeNBTWithOptions(expected, SerializeOptions{Compression: compression})
+		if err != nil {
+			t.Fatalf("%q: failed to serialize: %v", compression, err)
+		}
+		if compression != CompressionNone && bytes.Equal(data, decoderTestData()) {
+			t.Errorf("%q: expected compressed output", compression)
+		}
+
+		for _, parseCompression := range []Compression{compression, CompressionAuto} {
+			tag, err := ParseNBTWithOptions(data, ParseOptions{Compression: parseCompression})
+			if err != nil {
+				t.Fatalf("%q: failed to parse: %v", parseCompression, err)
+			}
+			if reserialized, _ := SerializeNBT(tag, false); !bytes.Equal(reserialized, decoderTestData()) {
+				t.Errorf("%q: round trip changed the data", parseCompression)
+			}
+		}
+
+		decoder, err := NewDecoderWithOptions(bytes.NewReader(data), ParseOptions{Compression: CompressionAuto})
+		if err != nil {
+			t.Fatalf("%q: failed to create decoder: %v", compression, err)
+		}
+		if _, err := decoder.Decode(); err != nil {
+			t.Errorf("%q: failed to decode: %v", compression, err)
+		}
+	}
+
+	if _, err := SerializeNBTWithOptions(expected, SerializeOptions{Compression: CompressionAuto}); err == nil {
+		t.Errorf("Expected auto compression to be rejected for serializing")
+	}
+}
+
+func TestParseTrailingDataPolicy(t *testing.T) {
+	data := append(decoderTestData(), 1, 2)
+
+	if _, err := ParseNBTWithOptions(data, ParseOptions{}); !errors.Is(err, ErrTrailingData) {
+		t.Errorf("Expected ErrTrailingData by default, got %v", err)
+	}
+
+	var warnings []TagParseError
+	opts := ParseOptions{
+		TrailingData: TrailingDataWarn,
+		Warn:         func(warning TagParseError) { warnings = append(warnings, warning) },
+	}
+	if _, err := ParseNBTWithOptions(data, opts); err != nil {
+		t.Errorf("Expected trailing data to be accepted with a warning, got %v", err)
+	}
+	if len(warnings) != 1 || !errors.Is(warnings[0], ErrTrailingData) {
+		t.Errorf("Expected one ErrTrailingData warning, got %v", warnings)
+	}
+
+	if _, err := ParseNBTWithOptions(data, ParseOptions{TrailingData: TrailingDataIgnore}); err != nil {
+		t.Errorf("Expected trailing data to be ignored, got %v", err)
+	}
+}
+
+func TestStringEncodingOverride(t *testing.T) {
+	root := &TagCompound{baseTag: baseTag{BTagCompound, ""}, Value: []NBTTag{
+		&TagString{baseTag: baseTag{BTagString, "s"}, Value: "a\x00"},
+		&TagEnd{baseTag: baseTag{BTagEnd, ""}},
+	}}
+	data, err := SerializeNBTWithOptions(root, SerializeOptions{StringEncoding: StringEncodingUTF8})
+	if err != nil {
+		t.Fatalf("Failed to serialize: %v", err)
+	}
+	// big-endian numbers, but the null byte is stored as is rather than as 0xC0 0x80
+	expected := []byte{byte(BTagString), 0, 1, 's', 0, 2, 'a', 0}
+	if !bytes.Contains(data, expected) {
+		t.Errorf("Expected %x in %x", expected, data)
+	}
+
+	tag, err := ParseNBTWithOptions(data, ParseOptions{StringEncoding: StringEncodingUTF8})
+	if err != nil {
+		t.Fatalf("Failed to parse: %v", err)
+	}
+	if value := tag.(*TagCompound).Value[0].(*TagString).Value; value != "a\x00" {
+		t.Errorf("Expected %q, got %q", "a\x00", value)
+	}
+}
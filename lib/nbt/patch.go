@@ -0,0 +1,283 @@
+package nbt
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+var (
+	// ErrPatchTestFailed is wrapped by the error of a test operation whose value does not match
+	ErrPatchTestFailed = errors.New("patch test failed")
+	// ErrPathNotFound is wrapped by the error of an operation whose path selects nothing
+	ErrPathNotFound = errors.New("path selects nothing")
+)
+
+// PatchOp names what a PatchOperation does
+type PatchOp string
+
+const (
+	// PatchAdd stores the value at a key, creating missing parents, or inserts it into a list or array.
+	// A path ending in an index inserts before that element, or appends if the index is the length;
+	// a path ending in [] appends.
+	PatchAdd PatchOp = "add"
+	// PatchRemove deletes every tag the path selects
+	PatchRemove PatchOp = "remove"
+	// PatchReplace stores the value in place of every tag the path selects, which must exist; it creates nothing
+	PatchReplace PatchOp = "replace"
+	// PatchTest checks that every tag the path selects equals the value, compared as by Equal without names
+	PatchTest PatchOp = "test"
+	// PatchCopy adds a copy of the one tag From selects at the path
+	PatchCopy PatchOp = "copy"
+	// PatchMove removes the one tag From selects and adds it at the path
+	PatchMove PatchOp = "move"
+)
+
+// PatchOperation is one step of a Patch, shaped like a JSON Patch (RFC 6902) operation
+// but addressed by NBT paths, see ParsePath:
+//
+//	{"op": "test", "path": "Data.Player.XpLevel", "value": {"type": "int", "name": "", "value": 30}}
+//	{"op": "replace", "path": "Data.Player.XpLevel", "value": "31"}
+//
+// The value is a tag in the JSON form of MarshalJSON, or a JSON string holding SNBT,
+// so it always carries its tag type.
+type PatchOperation struct {
+	Op    PatchOp `json:"op"`
+	Path  string  `json:"path"`
+	From  string  `json:"from,omitempty"`
+	Value NBTTag  `json:"value,omitempty"`
+}
+
+// Patch is a list of operations applied in order by ApplyPatch
+type Patch []PatchOperation
+
+// ParsePatch reads a patch from its JSON form, an array of operations
+func ParsePatch(data []byte) (Patch, error) {
+	var patch Patch
+	if err := json.Unmarshal(data, &patch); err != nil {
+		return nil, err
+	}
+	return patch, nil
+}
+
+// UnmarshalJSON implements json.Unmarshaler for PatchOperation
+func (op *PatchOperation) UnmarshalJSON(data []byte) error {
+	var temp struct {
+		Op    PatchOp         `json:"op"`
+		Path  string          `json:"path"`
+		From  string          `json:"from"`
+		Value json.RawMessage `json:"value"`
+	}
+	if err := json.Unmarshal(data, &temp); err != nil {
+		return err
+	}
+	op.Op, op.Path, op.From, op.Value = temp.Op, temp.Path, temp.From, nil
+	if len(temp.Value) == 0 || string(temp.Value) == "null" {
+		return nil
+	}
+	var snbt string
+	if json.Unmarshal(temp.Value, &snbt) == nil {
+		value, err := ParseSNBT(snbt)
+		if err != nil {
+			return fmt.Errorf("error parsing value of %s %s: %w", op.Op, op.Path, err)
+		}
+		op.Value = value
+		return nil
+	}
+	value, err := unmarshalNBTTag(temp.Value)
+	if err != nil {
+		return fmt.Errorf("error unmarshaling value of %s %s: %w", op.Op, op.Path, err)
+	}
+	op.Value = value
+	return nil
+}
+
+// ApplyPatch applies the operations of patch to root in order. It is all or nothing:
+// the operations are first tried on a copy, and only applied to root if every one of them succeeds.
+// Root is changed in place, so tags taken from the tree before stay part of it.
+// The error names the operation that failed; a failed test wraps ErrPatchTestFailed,
+// and a path that selects nothing ErrPathNotFound.
+func ApplyPatch(root NBTTag, patch Patch) error {
+	if err := patch.apply(Clone(root)); err != nil {
+		return err
+	}
+	return patch.apply(root)
+}
+
+func (patch Patch) apply(root NBTTag) error {
+	for i, op := range patch {
+		if err := op.apply(root); err != nil {
+			return fmt.Errorf("patch operation %d (%s %s): %w", i, op.Op, op.Path, err)
+		}
+	}
+	return nil
+}
+
+func (op PatchOperation) apply(root NBTTag) error {
+	path, err := ParsePath(op.Path)
+	if err != nil {
+		return err
+	}
+	switch op.Op {
+	case PatchAdd, PatchReplace, PatchTest:
+		if op.Value == nil {
+			return fmt.Errorf("missing value")
+		}
+	case PatchCopy, PatchMove:
+		if op.From == "" {
+			return fmt.Errorf("missing from")
+		}
+	case PatchRemove:
+	default:
+		return fmt.Errorf("unknown operation %q", op.Op)
+	}
+
+	switch op.Op {
+	case PatchAdd:
+		return addAt(root, path, op.Value)
+	case PatchRemove:
+		n, err := path.Remove(root)
+		if err == nil && n == 0 {
+			err = ErrPathNotFound
+		}
+		return err
+	case PatchReplace:
+		n, err := path.replace(root, op.Value)
+		if err == nil && n == 0 {
+			err = ErrPathNotFound
+		}
+		return err
+	case PatchTest:
+		matches := path.Get(root)
+		if len(matches) == 0 {
+			return ErrPathNotFound
+		}
+		for _, match := range matches {
+			if !equalValues(match, op.Value, EqualOptions{}) {
+				return fmt.Errorf("%w: found %s, expected %s", ErrPatchTestFailed, FormatSNBT(match), FormatSNBT(op.Value))
+			}
+		}
+		return nil
+	}
+
+	// copy and move
+	from, err := ParsePath(op.From)
+	if err != nil {
+		return err
+	}
+	sources := from.Get(root)
+	if len(sources) != 1 {
+		if len(sources) == 0 {
+			return fmt.Errorf("from %s: %w", op.From, ErrPathNotFound)
+		}
+		return fmt.Errorf("from %s selects %d tags, expected one", op.From, len(sources))
+	}
+	value := Clone(sources[0])
+	if op.Op == PatchMove {
+		if _, err := from.Remove(root); err != nil {
+			return err
+		}
+	}
+	return addAt(root, path, value)
+}
+
+// addAt stores value at a key or inserts it into a list or array, as described for PatchAdd
+func addAt(root NBTTag, path *Path, value NBTTag) error {
+	last := path.nodes[len(path.nodes)-1]
+	switch last.kind {
+	case nodeChild:
+		n, err := path.Set(root, value)
+		if err == nil && n == 0 {
+			err = ErrPathNotFound
+		}
+		return err
+	case nodeIndex, nodeAllElements:
+	default:
+		return fmt.Errorf("add needs a path ending in a key, an index or []")
+	}
+
+	parents := (&Path{path.nodes[:len(path.nodes)-1]}).Get(root)
+	count := 0
+	for _, parent := range parents {
+		n := elementCount(parent)
+		i := n
+		if last.kind == nodeIndex {
+			i = last.index
+			if i < 0 {
+				i += n
+			}
+			if i < 0 || i > n {
+				continue
+			}
+		}
+		if err := insertElement(parent, i, value); err != nil {
+			return err
+		}
+		count++
+	}
+	if count == 0 {
+		return ErrPathNotFound
+	}
+	return nil
+}
+
+// insertElement inserts a copy of value into a list or array before element i
+func insertElement(tag NBTTag, i int, value NBTTag) error {
+	switch t := tag.(type) {
+	case *TagList:
+		return t.Insert(i, Clone(value))
+	case *TagByteArray, *TagIntArray, *TagLongArray:
+		if err := setArrayElement(tag, -1, value); err != nil {
+			return err
+		}
+		switch t := tag.(type) {
+		case *TagByteArray:
+			moveLast(t.Value, i)
+		case *TagIntArray:
+			moveLast(t.Value, i)
+		case *TagLongArray:
+			moveLast(t.Value, i)
+		}
+		return nil
+	}
+	return fmt.Errorf("cannot insert into %s: %w", TagName[tag.Type()], ErrIncompatibleType)
+}
+
+// moveLast moves the last value to position i, shifting the ones after it along
+func moveLast[T any](values []T, i int) {
+	last := values[len(values)-1]
+	copy(values[i+1:], values[i:len(values)-1])
+	values[i] = last
+}
+
+// assignTag overwrites dst with the contents of src, which has the same type
+func assignTag(dst, src NBTTag) {
+	switch d := dst.(type) {
+	case *TagByte:
+		*d = *src.(*TagByte)
+	case *TagShort:
+		*d = *src.(*TagShort)
+	case *TagInt:
+		*d = *src.(*TagInt)
+	case *TagLong:
+		*d = *src.(*TagLong)
+	case *TagFloat:
+		*d = *src.(*TagFloat)
+	case *TagDouble:
+		*d = *src.(*TagDouble)
+	case *TagString:
+		*d = *src.(*TagString)
+	case *TagByteArray:
+		*d = *src.(*TagByteArray)
+	case *TagIntArray:
+		*d = *src.(*TagIntArray)
+	case *TagLongArray:
+		*d = *src.(*TagLongArray)
+	case *TagList:
+		*d = *src.(*TagList)
+	case *TagCompound:
+		*d = *src.(*TagCompound)
+	case *TagEnd:
+		*d = *src.(*TagEnd)
+	}
+}
@@ -0,0 +1,111 @@
+package nbt
+
+import (
+	"errors"
+	"testing"
+)
+
+func mustParsePatch(t *testing.T, text string) Patch {
+	t.Helper()
+	patch, err := ParsePatch([]byte(text))
+	if err != nil {
+		t.Fatalf("Failed to parse patch: %v", err)
+	}
+	return patch
+}
+
+func TestApplyPatch(t *testing.T) {
+	root := mustParseSNBT(t, `{Data: {Player: {XpLevel: 30, Inventory: [{Slot: 0b}, {Slot: 2b}], Heights: [I; 1, 3]}}}`)
+	patch := mustParsePatch(t, `[
+		{"op": "test", "path": "Data.Player.XpLevel", "value": {"type": "int", "name": "", "value": 30}},
+		{"op": "replace", "path": "Data.Player.XpLevel", "value": "31L"},
+		{"op": "add", "path": "Data.Player.Inventory[1]", "value": "{Slot: 1b}"},
+		{"op": "add", "path": "Data.Player.Inventory[]", "value": "{Slot: 3b}"},
+		{"op": "add", "path": "Data.Player.Heights[1]", "value": "2"},
+		{"op": "add", "path": "Data.Player.Stats.Deaths", "value": "0"},
+		{"op": "copy", "from": "Data.Player.XpLevel", "path": "Data.Player.OldXpLevel"},
+		{"op": "move", "from": "Data.Player.Inventory[0]", "path": "Data.Player.Offhand"},
+		{"op": "remove", "path": "Data.Player.Heights[-1]"}
+	]`)
+	if err := ApplyPatch(root, patch); err != nil {
+		t.Fatalf("Failed to apply patch: %v", err)
+	}
+	expected := `{Data:{Player:{XpLevel:31L,Inventory:[{Slot:1b},{Slot:2b},{Slot:3b}],Heights:[I;1,2],` +
+		`Stats:{Deaths:0},OldXpLevel:31L,Offhand:{Slot:0b}}}}`
+	if FormatSNBT(root) != expected {
+		t.Errorf("Expected %s\ngot      %s", expected, FormatSNBT(root))
+	}
+}
+
+func TestApplyPatchReplaceCreatesNothing(t *testing.T) {
+	root := mustParseSNBT(t, `{Inventory: [{id: "a", tag: {Damage: 1}}, {id: "b"}, {id: "c", tag: {}}]}`)
+	if err := ApplyPatch(root, mustParsePatch(t, `[{"op": "replace", "path": "Inventory[].tag.Damage", "value": "5"}]`)); err != nil {
+		t.Fatalf("Failed to apply patch: %v", err)
+	}
+	expected := `{Inventory:[{id:"a",tag:{Damage:5}},{id:"b"},{id:"c",tag:{}}]}`
+	if FormatSNBT(root) != expected {
+		t.Errorf("Expected only the existing Damage to be replaced, %s\ngot %s", expected, FormatSNBT(root))
+	}
+
+	err := ApplyPatch(root, mustParsePatch(t, `[{"op": "replace", "path": "Inventory[1].tag.Damage", "value": "5"}]`))
+	if !errors.Is(err, ErrPathNotFound) || FormatSNBT(root) != expected {
+		t.Errorf("Expected replacing a missing key to fail with ErrPathNotFound, got %v, %s", err, FormatSNBT(root))
+	}
+}
+
+func TestApplyPatchKeepsSubtrees(t *testing.T) {
+	root := mustParseSNBT(t, `{Inventory: [{Slot: 0b}], XpLevel: 30}`)
+	inventory := root.(*TagCompound).Get("Inventory")
+	patch := mustParsePatch(t, `[{"op": "add", "path": "Inventory[]", "value": "{Slot: 1b}"}, {"op": "replace", "path": "XpLevel", "value": "31"}]`)
+	if err := ApplyPatch(root, patch); err != nil {
+		t.Fatalf("Failed to apply patch: %v", err)
+	}
+	if root.(*TagCompound).Get("Inventory") != inventory || FormatSNBT(inventory) != `[{Slot:0b},{Slot:1b}]` {
+		t.Errorf("Expected the patch to change the list taken before it, got %s", FormatSNBT(inventory))
+	}
+}
+
+func TestApplyPatchIsAtomic(t *testing.T) {
+	const original = `{XpLevel: 30, Inventory: [{Slot: 0b}]}`
+	tests := []struct {
+		patch string
+		err   error
+	}{
+		{`[{"op": "remove", "path": "Inventory"}, {"op": "test", "path": "XpLevel", "value": "31"}]`, ErrPatchTestFailed},
+		{`[{"op": "replace", "path": "XpLevel", "value": "31"}, {"op": "remove", "path": "Missing"}]`, ErrPathNotFound},
+		{`[{"op": "add", "path": "Inventory[]", "value": "{Slot: 1b}"}, {"op": "add", "path": "Inventory[0]", "value": "1"}]`, ErrIncompatibleType},
+		{`[{"op": "add", "path": "XpLevel", "value": "1"}, {"op": "replace", "path": "a..b", "value": "1"}]`, ErrInvalidPath},
+	}
+	for _, test := range tests {
+		root := mustParseSNBT(t, original)
+		err := ApplyPatch(root, mustParsePatch(t, test.patch))
+		if !errors.Is(err, test.err) {
+			t.Errorf("Expected %v applying %s, got %v", test.err, test.patch, err)
+		}
+		if FormatSNBT(root) != FormatSNBT(mustParseSNBT(t, original)) {
+			t.Errorf("Expected a failed patch to leave the tree unchanged, got %s", FormatSNBT(root))
+		}
+	}
+}
+
+func TestParsePatchErrors(t *testing.T) {
+	for _, text := range []string{
+		`{"op": "add"}`,
+		`[{"op": "add", "path": "a", "value": "{unclosed"}]`,
+		`[{"op": "add", "path": "a", "value": {"type": "nope"}}]`,
+	} {
+		if _, err := ParsePatch([]byte(text)); err == nil {
+			t.Errorf("Expected an error parsing %s", text)
+		}
+	}
+	root := mustParseSNBT(t, `{a: 1}`)
+	for _, text := range []string{
+		`[{"op": "frobnicate", "path": "a"}]`,
+		`[{"op": "replace", "path": "a"}]`,
+		`[{"op": "move", "path": "b"}]`,
+	} {
+		if err := ApplyPatch(root, mustParsePatch(t, text)); err == nil {
+			t.Errorf("Expected an error applying %s", text)
+		}
+	}
+}
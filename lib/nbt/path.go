@@ -0,0 +1,671 @@
+package nbt
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// ErrInvalidPath is wrapped by errors for text that is not a valid NBT path
+var ErrInvalidPath = errors.New("invalid NBT path")
+
+// Path is a parsed NBT path in the syntax of Minecraft's /data command, such as
+// Inventory[{Slot:0b}].components."minecraft:custom_name" or Level.Sections[].Y.
+//
+// A path is a sequence of nodes:
+//
+//   - name or "quoted name" selects a child of a compound, separated from a previous node by a dot
+//   - name{...} selects the child only if it is a compound matching the SNBT filter
+//   - {...} at the start selects the root only if it matches the filter
+//   - [i] selects element i of a list or array, counting from the end if negative
+//   - [] selects every element of a list or array
+//   - [{...}] selects the compound elements of a list matching the filter
+//
+// A filter matches a compound holding at least the filter's keys with matching values,
+// and a list in a filter matches a list holding a match for each of its elements.
+type Path struct {
+	nodes []pathNode
+}
+
+type pathNodeKind int
+
+const (
+	nodeChild pathNodeKind = iota
+	nodeMatchChild
+	nodeMatchRoot
+	nodeIndex
+	nodeAllElements
+	nodeMatchElements
+)
+
+type pathNode struct {
+	kind   pathNodeKind
+	name   string
+	index  int
+	filter *TagCompound
+}
+
+// ParsePath parses an NBT path, see Path
+func ParsePath(text string) (*Path, error) {
+	s := &snbtScanner{text: text}
+	path := &Path{}
+	for s.pos < len(s.text) {
+		start := s.pos
+		node, err := readPathNode(s, len(path.nodes) == 0)
+		if err != nil {
+			if errors.Is(err, ErrInvalidSNBT) {
+				return nil, fmt.Errorf("%w: bad filter at position %d: %w", ErrInvalidPath, start, err)
+			}
+			return nil, err
+		}
+		path.nodes = append(path.nodes, node)
+	}
+	if len(path.nodes) == 0 {
+		return nil, fmt.Errorf("%w: empty path", ErrInvalidPath)
+	}
+	return path, nil
+}
+
+func pathErrorf(s *snbtScanner, format string, args ...any) error {
+	return fmt.Errorf("%w: %s at position %d", ErrInvalidPath, fmt.Sprintf(format, args...), s.pos)
+}
+
+// isUnquotedPathChar accepts what Minecraft accepts in an unquoted path key, such as the colon in minecraft:custom_name
+func isUnquotedPathChar(r rune) bool {
+	return !strings.ContainsRune(" \"'[]{}.", r)
+}
+
+func readPathNode(s *snbtScanner, first bool) (pathNode, error) {
+	c := s.text[s.pos]
+	switch {
+	case c == '{' && first:
+		filter, err := s.readCompound()
+		return pathNode{kind: nodeMatchRoot, filter: filter}, err
+	case c == '[' && !first:
+		return readPathBrackets(s)
+	case c == '.' && !first:
+		s.pos++
+		if s.pos >= len(s.text) {
+			return pathNode{}, pathErrorf(s, "expected a key after the dot")
+		}
+	case !first:
+		return pathNode{}, pathErrorf(s, "expected . or [ between nodes")
+	}
+
+	var name string
+	switch c := s.text[s.pos]; {
+	case c == '"' || c == '\'':
+		quoted, err := s.readQuoted()
+		if err != nil {
+			return pathNode{}, pathErrorf(s, "unterminated quoted key")
+		}
+		name = quoted
+	case isUnquotedPathChar(rune(c)):
+		name = s.readUnquoted(isUnquotedPathChar)
+	default:
+		return pathNode{}, pathErrorf(s, "unexpected %q", c)
+	}
+	if s.pos < len(s.text) && s.text[s.pos] == '{' {
+		filter, err := s.readCompound()
+		return pathNode{kind: nodeMatchChild, name: name, filter: filter}, err
+	}
+	return pathNode{kind: nodeChild, name: name}, nil
+}
+
+func readPathBrackets(s *snbtScanner) (pathNode, error) {
+	s.pos++
+	var node pathNode
+	switch c := s.peek(); {
+	case c == ']':
+		node = pathNode{kind: nodeAllElements}
+	case c == '{':
+		filter, err := s.readCompound()
+		if err != nil {
+			return node, err
+		}
+		node = pathNode{kind: nodeMatchElements, filter: filter}
+	default:
+		word := s.readUnquoted(func(r rune) bool { return r == '-' || r >= '0' && r <= '9' })
+		index, err := strconv.Atoi(word)
+		if err != nil {
+			return node, pathErrorf(s, "expected an index, a filter or ]")
+		}
+		node = pathNode{kind: nodeIndex, index: index}
+	}
+	if s.peek() != ']' {
+		return node, pathErrorf(s, "expected ]")
+	}
+	s.pos++
+	return node, nil
+}
+
+// String formats the path so that ParsePath reads it back
+func (p *Path) String() string {
+	var b strings.Builder
+	for i, node := range p.nodes {
+		switch node.kind {
+		case nodeChild, nodeMatchChild:
+			if i > 0 {
+				b.WriteByte('.')
+			}
+			b.WriteString(formatPathKey(node.name))
+			if node.kind == nodeMatchChild {
+				b.WriteString(FormatSNBT(node.filter))
+			}
+		case nodeMatchRoot:
+			b.WriteString(FormatSNBT(node.filter))
+		case nodeIndex:
+			fmt.Fprintf(&b, "[%d]", node.index)
+		case nodeAllElements:
+			b.WriteString("[]")
+		case nodeMatchElements:
+			fmt.Fprintf(&b, "[%s]", FormatSNBT(node.filter))
+		}
+	}
+	return b.String()
+}
+
+// Get returns every tag the path selects below root, in order.
+// Elements of byte, int and long arrays are returned as new tags, so changing them does not change the array.
+func (p *Path) Get(root NBTTag) []NBTTag {
+	tags := []NBTTag{root}
+	for _, node := range p.nodes {
+		var next []NBTTag
+		for _, tag := range tags {
+			next = node.appendMatches(next, tag)
+		}
+		tags = next
+	}
+	return tags
+}
+
+// Set stores a copy of value at every place the path selects, creating missing compounds and lists
+// along the way as Minecraft's /data modify ... set does. It returns how many places were set.
+// A path that selects nothing leaves the tree unchanged, the parents created for it are taken out again.
+// A value that does not fit its place, such as a string in a list of ints, fails with ErrIncompatibleType
+// and also leaves the tree unchanged.
+func (p *Path) Set(root NBTTag, value NBTTag) (int, error) {
+	last := p.nodes[len(p.nodes)-1]
+	if last.kind == nodeMatchRoot {
+		return 0, fmt.Errorf("cannot replace the root tag")
+	}
+	var created undoLog
+	count, err := p.store(p.getOrCreateParents(root, &created), value)
+	if count == 0 {
+		created.undo()
+	}
+	return count, err
+}
+
+// replace stores a copy of value in place of every tag the path selects, creating nothing,
+// and returns how many were replaced
+func (p *Path) replace(root NBTTag, value NBTTag) (int, error) {
+	last := p.nodes[len(p.nodes)-1]
+	if last.kind == nodeMatchRoot {
+		return 0, fmt.Errorf("cannot replace the root tag")
+	}
+	parents := (&Path{p.nodes[:len(p.nodes)-1]}).Get(root)
+	if last.kind == nodeChild {
+		// set would add the key to the compounds without it
+		parents = slices.DeleteFunc(parents, func(parent NBTTag) bool {
+			compound, ok := parent.(*TagCompound)
+			return ok && !compound.Has(last.name)
+		})
+	}
+	return p.store(parents, value)
+}
+
+// store sets value at the last node in each of parents. Every place is checked before
+// anything is stored, so a value that does not fit one of them changes nothing.
+func (p *Path) store(parents []NBTTag, value NBTTag) (int, error) {
+	last := p.nodes[len(p.nodes)-1]
+	for _, parent := range parents {
+		if _, err := last.set(parent, value, true); err != nil {
+			return 0, err
+		}
+	}
+	count := 0
+	for _, parent := range parents {
+		n, err := last.set(parent, value, false)
+		count += n
+		if err != nil {
+			return count, err
+		}
+	}
+	return count, nil
+}
+
+// Remove deletes every tag the path selects and returns how many were removed
+func (p *Path) Remove(root NBTTag) (int, error) {
+	last := p.nodes[len(p.nodes)-1]
+	if last.kind == nodeMatchRoot {
+		return 0, fmt.Errorf("cannot remove the root tag")
+	}
+	parents := (&Path{p.nodes[:len(p.nodes)-1]}).Get(root)
+	count := 0
+	for _, parent := range parents {
+		count += last.remove(parent)
+	}
+	return count, nil
+}
+
+// Append adds a copy of value to the end of every list or array the path selects,
+// creating a missing list as Minecraft's /data modify ... append does.
+// It returns how many lists were appended to.
+func (p *Path) Append(root NBTTag, value NBTTag) (int, error) {
+	targets := p.getOrCreate(root, func() NBTTag { return NewList("", BTagEnd) }, nil)
+	count := 0
+	for _, target := range targets {
+		switch t := target.(type) {
+		case *TagList:
+			if err := t.Append(Clone(value)); err != nil {
+				return count, err
+			}
+		case *TagByteArray, *TagIntArray, *TagLongArray:
+			if err := setArrayElement(t, -1, value); err != nil {
+				return count, err
+			}
+		default:
+			return count, fmt.Errorf("cannot append to %s: %w", TagName[target.Type()], ErrIncompatibleType)
+		}
+		count++
+	}
+	return count, nil
+}
+
+// getOrCreateParents returns the tags selected by every node but the last,
+// creating missing ones of the kind the next node expects
+func (p *Path) getOrCreateParents(root NBTTag, created *undoLog) []NBTTag {
+	parentPath := &Path{p.nodes[:len(p.nodes)-1]}
+	last := p.nodes[len(p.nodes)-1]
+	if len(parentPath.nodes) == 0 {
+		return []NBTTag{root}
+	}
+	return parentPath.getOrCreate(root, last.preferredParent, created)
+}
+
+// getOrCreate returns the tags selected by the path, creating missing ones.
+// Each node creates what its successor expects, and the last one what create returns.
+// How to take each created tag out again is added to created, if it is not nil.
+func (p *Path) getOrCreate(root NBTTag, create func() NBTTag, created *undoLog) []NBTTag {
+	tags := []NBTTag{root}
+	for i, node := range p.nodes {
+		newTag := create
+		if i+1 < len(p.nodes) {
+			newTag = p.nodes[i+1].preferredParent
+		}
+		var next []NBTTag
+		for _, tag := range tags {
+			next = node.appendOrCreate(next, tag, newTag, created)
+		}
+		tags = next
+	}
+	return tags
+}
+
+// undoLog holds the steps that take tags created by getOrCreate out of the tree again
+type undoLog []func()
+
+func (u *undoLog) add(step func()) {
+	if u != nil {
+		*u = append(*u, step)
+	}
+}
+
+// undo runs the steps in reverse, so that each tag is taken out of the parent it was added to
+func (u undoLog) undo() {
+	for i := len(u) - 1; i >= 0; i-- {
+		u[i]()
+	}
+}
+
+// preferredParent returns the empty tag this node can select from
+func (node pathNode) preferredParent() NBTTag {
+	switch node.kind {
+	case nodeIndex, nodeAllElements, nodeMatchElements:
+		return NewList("", BTagEnd)
+	default:
+		return NewCompound("")
+	}
+}
+
+// appendMatches appends the tags this node selects from tag
+func (node pathNode) appendMatches(matches []NBTTag, tag NBTTag) []NBTTag {
+	switch node.kind {
+	case nodeChild:
+		if compound, ok := tag.(*TagCompound); ok {
+			if child := compound.Get(node.name); child != nil {
+				matches = append(matches, child)
+			}
+		}
+	case nodeMatchChild:
+		if compound, ok := tag.(*TagCompound); ok {
+			if child := compound.Get(node.name); child != nil && matchesFilter(node.filter, child) {
+				matches = append(matches, child)
+			}
+		}
+	case nodeMatchRoot:
+		if matchesFilter(node.filter, tag) {
+			matches = append(matches, tag)
+		}
+	case nodeIndex:
+		if i, ok := elementIndex(tag, node.index); ok {
+			matches = append(matches, elementAt(tag, i))
+		}
+	case nodeAllElements:
+		for i := range elementCount(tag) {
+			matches = append(matches, elementAt(tag, i))
+		}
+	case nodeMatchElements:
+		if list, ok := tag.(*TagList); ok {
+			for _, item := range list.Value {
+				if matchesFilter(node.filter, item) {
+					matches = append(matches, item)
+				}
+			}
+		}
+	}
+	return matches
+}
+
+// appendOrCreate is appendMatches, but adds a tag from create, or a copy of the filter, where nothing matches
+func (node pathNode) appendOrCreate(matches []NBTTag, tag NBTTag, create func() NBTTag, created *undoLog) []NBTTag {
+	n := len(matches)
+	matches = node.appendMatches(matches, tag)
+	if len(matches) > n {
+		return matches
+	}
+	switch node.kind {
+	case nodeChild, nodeMatchChild:
+		compound, ok := tag.(*TagCompound)
+		if !ok || compound.Has(node.name) {
+			// a child of another type, or one that does not match the filter, is left alone
+			return matches
+		}
+		newTag := create()
+		if node.kind == nodeMatchChild {
+			newTag = Clone(node.filter)
+		}
+		newTag.SetName(node.name)
+		compound.Set(newTag)
+		created.add(func() { compound.Delete(node.name) })
+		return append(matches, newTag)
+	case nodeAllElements, nodeMatchElements:
+		list, ok := tag.(*TagList)
+		if !ok || node.kind == nodeAllElements && len(list.Value) > 0 {
+			return matches
+		}
+		newTag := create()
+		if node.kind == nodeMatchElements {
+			newTag = Clone(node.filter)
+		}
+		elementType := list.ElementType
+		if list.Append(newTag) != nil {
+			return matches
+		}
+		created.add(func() {
+			list.Value[len(list.Value)-1] = nil
+			list.Value = list.Value[:len(list.Value)-1]
+			list.ElementType = elementType
+		})
+		return append(matches, newTag)
+	}
+	return matches
+}
+
+// set stores a copy of value at the places this node selects in parent.
+// With dryRun it only counts the places and checks that the value fits them.
+func (node pathNode) set(parent NBTTag, value NBTTag, dryRun bool) (int, error) {
+	switch node.kind {
+	case nodeChild, nodeMatchChild:
+		compound, ok := parent.(*TagCompound)
+		if !ok {
+			return 0, nil
+		}
+		if node.kind == nodeMatchChild {
+			if child := compound.Get(node.name); child == nil || !matchesFilter(node.filter, child) {
+				return 0, nil
+			}
+		}
+		if dryRun {
+			return 1, nil
+		}
+		newTag := Clone(value)
+		newTag.SetName(node.name)
+		compound.Set(newTag)
+		return 1, nil
+	case nodeIndex:
+		i, ok := elementIndex(parent, node.index)
+		if !ok {
+			return 0, nil
+		}
+		if dryRun {
+			return 1, checkElement(parent, value)
+		}
+		return 1, setElement(parent, i, value)
+	case nodeAllElements, nodeMatchElements:
+		count := 0
+		for i := range elementCount(parent) {
+			if node.kind == nodeMatchElements && !matchesFilter(node.filter, elementAt(parent, i)) {
+				continue
+			}
+			if dryRun {
+				count++
+				continue
+			}
+			if err := setElement(parent, i, value); err != nil {
+				return count, err
+			}
+			count++
+		}
+		if dryRun && count > 0 {
+			return count, checkElement(parent, value)
+		}
+		return count, nil
+	}
+	return 0, nil
+}
+
+// remove deletes the tags this node selects in parent
+func (node pathNode) remove(parent NBTTag) int {
+	switch node.kind {
+	case nodeChild:
+		if compound, ok := parent.(*TagCompound); ok && compound.Delete(node.name) != nil {
+			return 1
+		}
+	case nodeMatchChild:
+		if compound, ok := parent.(*TagCompound); ok {
+			if child := compound.Get(node.name); child != nil && matchesFilter(node.filter, child) {
+				compound.Delete(node.name)
+				return 1
+			}
+		}
+	case nodeIndex:
+		if i, ok := elementIndex(parent, node.index); ok {
+			removeElements(parent, func(j int) bool { return j == i })
+			return 1
+		}
+	case nodeAllElements:
+		return removeElements(parent, func(int) bool { return true })
+	case nodeMatchElements:
+		if list, ok := parent.(*TagList); ok {
+			return removeElements(parent, func(j int) bool { return matchesFilter(node.filter, list.Value[j]) })
+		}
+	}
+	return 0
+}
+
+// elementCount returns the length of a list or array, or 0 for other tags
+func elementCount(tag NBTTag) int {
+	switch t := tag.(type) {
+	case *TagList:
+		return len(t.Value)
+	case *TagByteArray:
+		return len(t.Value)
+	case *TagIntArray:
+		return len(t.Value)
+	case *TagLongArray:
+		return len(t.Value)
+	}
+	return 0
+}
+
+// elementIndex resolves a possibly negative index into a list or array
+func elementIndex(tag NBTTag, index int) (int, bool) {
+	n := elementCount(tag)
+	if index < 0 {
+		index += n
+	}
+	return index, index >= 0 && index < n
+}
+
+// elementAt returns element i of a list, or a new tag holding element i of an array
+func elementAt(tag NBTTag, i int) NBTTag {
+	switch t := tag.(type) {
+	case *TagList:
+		return t.Value[i]
+	case *TagByteArray:
+		return NewByte("", t.Value[i])
+	case *TagIntArray:
+		return NewInt("", t.Value[i])
+	case *TagLongArray:
+		return NewLong("", t.Value[i])
+	}
+	return nil
+}
+
+// setElement replaces element i of a list or array with a copy of value
+func setElement(tag NBTTag, i int, value NBTTag) error {
+	list, ok := tag.(*TagList)
+	if !ok {
+		return setArrayElement(tag, i, value)
+	}
+	if err := checkElement(tag, value); err != nil {
+		return err
+	}
+	newTag := Clone(value)
+	newTag.SetName("")
+	list.ElementType = value.Type()
+	list.Value[i] = newTag
+	return nil
+}
+
+// checkElement returns the error setElement fails with when value does not fit an element of a list or array
+func checkElement(tag NBTTag, value NBTTag) error {
+	list, ok := tag.(*TagList)
+	if !ok {
+		_, err := arrayElementValue(tag, value)
+		return err
+	}
+	if value.Type() != list.ElementType && len(list.Value) > 1 {
+		return fmt.Errorf("cannot store %s in a list of %s: %w", TagName[value.Type()], TagName[list.ElementType], ErrIncompatibleType)
+	}
+	return nil
+}
+
+// arrayElementValue returns value as an element of an array,
+// which it can be if it is an integer no wider than the array's elements
+func arrayElementValue(tag NBTTag, value NBTTag) (int64, error) {
+	number, _, isFloat, ok := numericValue(value)
+	if !ok || isFloat || value.Type() > arrayElementType(tag.Type()) {
+		return 0, fmt.Errorf("cannot store %s in %s: %w", TagName[value.Type()], TagName[tag.Type()], ErrIncompatibleType)
+	}
+	return number, nil
+}
+
+// setArrayElement stores value as element i of an array, or appends it if i is -1.
+// The value must be an integer no wider than the array's elements.
+func setArrayElement(tag NBTTag, i int, value NBTTag) error {
+	number, err := arrayElementValue(tag, value)
+	if err != nil {
+		return err
+	}
+	switch t := tag.(type) {
+	case *TagByteArray:
+		if i < 0 {
+			t.Value = append(t.Value, byte(number))
+		} else {
+			t.Value[i] = byte(number)
+		}
+	case *TagIntArray:
+		if i < 0 {
+			t.Value = append(t.Value, int32(number))
+		} else {
+			t.Value[i] = int32(number)
+		}
+	case *TagLongArray:
+		if i < 0 {
+			t.Value = append(t.Value, number)
+		} else {
+			t.Value[i] = number
+		}
+	}
+	return nil
+}
+
+// removeElements deletes the elements of a list or array at the positions selected by del
+func removeElements(tag NBTTag, del func(i int) bool) int {
+	count := 0
+	keep := func(i int) bool {
+		if del(i) {
+			count++
+			return false
+		}
+		return true
+	}
+	switch t := tag.(type) {
+	case *TagList:
+		t.Value = filterElements(t.Value, keep)
+	case *TagByteArray:
+		t.Value = filterElements(t.Value, keep)
+	case *TagIntArray:
+		t.Value = filterElements(t.Value, keep)
+	case *TagLongArray:
+		t.Value = filterElements(t.Value, keep)
+	}
+	return count
+}
+
+func filterElements[T any](values []T, keep func(i int) bool) []T {
+	kept := values[:0]
+	for i, value := range values {
+		if keep(i) {
+			kept = append(kept, value)
+		}
+	}
+	clear(values[len(kept):])
+	return kept
+}
+
+// matchesFilter reports whether tag matches a filter from a path,
+// the way Minecraft compares NBT partially: compounds need the filter's keys, lists a match for each filter element
+func matchesFilter(filter NBTTag, tag NBTTag) bool {
+	if filter.Type() != tag.Type() {
+		return false
+	}
+	switch f := filter.(type) {
+	case *TagCompound:
+		compound := tag.(*TagCompound)
+		for name, child := range f.All() {
+			actual := compound.Get(name)
+			if actual == nil || !matchesFilter(child, actual) {
+				return false
+			}
+		}
+		return true
+	case *TagList:
+		list := tag.(*TagList)
+		if len(f.Value) == 0 {
+			return len(list.Value) == 0
+		}
+		for _, item := range f.Value {
+			if !slices.ContainsFunc(list.Value, func(actual NBTTag) bool { return matchesFilter(item, actual) }) {
+				return false
+			}
+		}
+		return true
+	}
+	return equalValues(filter, tag, EqualOptions{})
+}
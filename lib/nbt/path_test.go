@@ -0,0 +1,215 @@
+package nbt
+
+import (
+	"errors"
+	"slices"
+	"testing"
+)
+
+func mustParseSNBT(t *testing.T, text string) NBTTag {
+	t.Helper()
+	tag, err := ParseSNBT(text)
+	if err != nil {
+		t.Fatalf("Failed to parse SNBT %s: %v", text, err)
+	}
+	return tag
+}
+
+func mustParsePath(t *testing.T, text string) *Path {
+	t.Helper()
+	path, err := ParsePath(text)
+	if err != nil {
+		t.Fatalf("Failed to parse path %s: %v", text, err)
+	}
+	return path
+}
+
+func pathTestData(t *testing.T) NBTTag {
+	return mustParseSNBT(t, `{
+		Inventory: [
+			{Slot: 0b, id: "minecraft:stone", components: {"minecraft:custom_name": "Rock"}},
+			{Slot: 1b, id: "minecraft:dirt"}
+		],
+		Level: {Sections: [{Y: -4b}, {Y: 0b}, {Y: 3b}]},
+		UUID: [I; 10, 20, 30, 40]
+	}`)
+}
+
+func getSNBT(t *testing.T, root NBTTag, path string) []string {
+	t.Helper()
+	var values []string
+	for _, tag := range mustParsePath(t, path).Get(root) {
+		values = append(values, FormatSNBT(tag))
+	}
+	return values
+}
+
+func TestPathGet(t *testing.T) {
+	root := pathTestData(t)
+	tests := []struct {
+		path     string
+		expected []string
+	}{
+		{`Inventory[{Slot:0b}].components."minecraft:custom_name"`, []string{`"Rock"`}},
+		{`Inventory[{Slot:0b}].components.minecraft:custom_name`, []string{`"Rock"`}},
+		{`Level.Sections[].Y`, []string{"-4b", "0b", "3b"}},
+		{`Level.Sections[-1].Y`, []string{"3b"}},
+		{`Inventory[1].id`, []string{`"minecraft:dirt"`}},
+		{`Inventory[2]`, nil},
+		{`UUID[1]`, []string{"20"}},
+		{`UUID[]`, []string{"10", "20", "30", "40"}},
+		{`Level{Sections:[{Y:0b}]}.Sections[0]`, []string{"{Y:-4b}"}},
+		{`Level{Sections:[{Y:9b}]}`, nil},
+		{`{UUID:[I;10,20,30,40]}.Inventory[{id:"minecraft:dirt"}].Slot`, []string{"1b"}},
+		{`Missing.Child`, nil},
+	}
+	for _, test := range tests {
+		if values := getSNBT(t, root, test.path); !slices.Equal(values, test.expected) {
+			t.Errorf("%s: expected %v, got %v", test.path, test.expected, values)
+		}
+	}
+}
+
+func TestPathSet(t *testing.T) {
+	root := pathTestData(t)
+
+	count, err := mustParsePath(t, `Level.Sections[].Y`).Set(root, NewByte("", 7))
+	if err != nil || count != 3 {
+		t.Fatalf("Expected 3 values set, got %d: %v", count, err)
+	}
+	if values := getSNBT(t, root, `Level.Sections[].Y`); !slices.Equal(values, []string{"7b", "7b", "7b"}) {
+		t.Errorf("Expected every Y to be 7b, got %v", values)
+	}
+
+	// missing compounds along the way are created
+	if _, err := mustParsePath(t, `Data.Player.XpLevel`).Set(root, NewInt("", 5)); err != nil {
+		t.Fatalf("Failed to set a new path: %v", err)
+	}
+	if values := getSNBT(t, root, `Data`); !slices.Equal(values, []string{"{Player:{XpLevel:5}}"}) {
+		t.Errorf("Expected the created compounds, got %v", values)
+	}
+
+	if _, err := mustParsePath(t, `UUID[-1]`).Set(root, NewInt("", 99)); err != nil {
+		t.Fatalf("Failed to set an array element: %v", err)
+	}
+	if values := getSNBT(t, root, `UUID`); !slices.Equal(values, []string{"[I;10,20,30,99]"}) {
+		t.Errorf("Expected the last UUID element to be 99, got %v", values)
+	}
+
+	if _, err := mustParsePath(t, `Inventory[0]`).Set(root, NewString("", "x")); !errors.Is(err, ErrIncompatibleType) {
+		t.Errorf("Expected ErrIncompatibleType for a string in a list of compounds, got %v", err)
+	}
+	if _, err := mustParsePath(t, `UUID[0]`).Set(root, NewLong("", 1)); !errors.Is(err, ErrIncompatibleType) {
+		t.Errorf("Expected ErrIncompatibleType for a long in an int array, got %v", err)
+	}
+}
+
+func TestPathSetNoMatch(t *testing.T) {
+	const original = `{a: {x: 1}, list: [I; 1], empty: []}`
+	root := mustParseSNBT(t, original)
+	for _, path := range []string{`a.b[0].c`, `a.x.y`, `missing[{id: 1}][0]`, `list[5]`, `empty[{id: 1}].b[0]`} {
+		count, err := mustParsePath(t, path).Set(root, NewInt("", 2))
+		if count != 0 || err != nil {
+			t.Errorf("Expected %s to set nothing, got %d: %v", path, count, err)
+		}
+		if FormatSNBT(root) != FormatSNBT(mustParseSNBT(t, original)) {
+			t.Errorf("Expected setting %s to leave the tree unchanged, got %s", path, FormatSNBT(root))
+			root = mustParseSNBT(t, original)
+		}
+	}
+}
+
+func TestPathSetIncompatibleChangesNothing(t *testing.T) {
+	// the first v takes an int and the second gets a new list, but the last v holds strings
+	const original = `{l: [{v: [1, 2]}, {}, {v: ["a", "b"]}]}`
+	root := mustParseSNBT(t, original)
+	for _, path := range []string{`l[].v[0]`, `l[].v[]`} {
+		count, err := mustParsePath(t, path).Set(root, NewInt("", 5))
+		if count != 0 || !errors.Is(err, ErrIncompatibleType) {
+			t.Errorf("Expected setting %s to fail with ErrIncompatibleType, got %d: %v", path, count, err)
+		}
+		if FormatSNBT(root) != FormatSNBT(mustParseSNBT(t, original)) {
+			t.Errorf("Expected setting %s to leave the tree unchanged, got %s", path, FormatSNBT(root))
+			root = mustParseSNBT(t, original)
+		}
+	}
+}
+
+func TestPathSetCopiesValue(t *testing.T) {
+	root := mustParseSNBT(t, `{a: {}, b: {}}`)
+	value := mustParseSNBT(t, `{n: 1}`)
+	for _, path := range []string{"a.v", "b.v"} {
+		if _, err := mustParsePath(t, path).Set(root, value); err != nil {
+			t.Fatalf("Failed to set %s: %v", path, err)
+		}
+	}
+	root.(*TagCompound).Get("a").(*TagCompound).Get("v").(*TagCompound).Set(NewInt("n", 2))
+	if values := getSNBT(t, root, `b.v.n`); !slices.Equal(values, []string{"1"}) {
+		t.Errorf("Expected b.v to be a separate copy, got %v", values)
+	}
+}
+
+func TestPathRemove(t *testing.T) {
+	root := pathTestData(t)
+	count, err := mustParsePath(t, `Inventory[{id:"minecraft:stone"}]`).Remove(root)
+	if err != nil || count != 1 {
+		t.Fatalf("Expected 1 element removed, got %d: %v", count, err)
+	}
+	if values := getSNBT(t, root, `Inventory[].Slot`); !slices.Equal(values, []string{"1b"}) {
+		t.Errorf("Expected only slot 1 to remain, got %v", values)
+	}
+
+	if count, _ := mustParsePath(t, `Level.Sections[0].Y`).Remove(root); count != 1 {
+		t.Errorf("Expected 1 value removed, got %d", count)
+	}
+	if count, _ := mustParsePath(t, `UUID[0]`).Remove(root); count != 1 {
+		t.Errorf("Expected 1 array element removed, got %d", count)
+	}
+	if values := getSNBT(t, root, `UUID`); !slices.Equal(values, []string{"[I;20,30,40]"}) {
+		t.Errorf("Expected the first UUID element removed, got %v", values)
+	}
+	if count, _ := mustParsePath(t, `Missing`).Remove(root); count != 0 {
+		t.Errorf("Expected nothing removed for a missing key, got %d", count)
+	}
+}
+
+func TestPathAppend(t *testing.T) {
+	root := pathTestData(t)
+	if _, err := mustParsePath(t, `Inventory`).Append(root, mustParseSNBT(t, `{Slot: 2b}`)); err != nil {
+		t.Fatalf("Failed to append to a list: %v", err)
+	}
+	if values := getSNBT(t, root, `Inventory[-1]`); !slices.Equal(values, []string{"{Slot:2b}"}) {
+		t.Errorf("Expected the appended compound last, got %v", values)
+	}
+	if _, err := mustParsePath(t, `UUID`).Append(root, NewInt("", 50)); err != nil {
+		t.Fatalf("Failed to append to an array: %v", err)
+	}
+	if _, err := mustParsePath(t, `Tags`).Append(root, NewString("", "new")); err != nil {
+		t.Fatalf("Failed to append to a missing list: %v", err)
+	}
+	if values := getSNBT(t, root, `Tags`); !slices.Equal(values, []string{`["new"]`}) {
+		t.Errorf("Expected a created list, got %v", values)
+	}
+	if _, err := mustParsePath(t, `Level`).Append(root, NewInt("", 1)); !errors.Is(err, ErrIncompatibleType) {
+		t.Errorf("Expected ErrIncompatibleType appending to a compound, got %v", err)
+	}
+}
+
+func TestParsePathRoundTrip(t *testing.T) {
+	for _, text := range []string{
+		`Inventory[{Slot:0b}].components."minecraft:custom_name"`,
+		`Level.Sections[].Y`,
+		`{a:1}.b[-1]`,
+		`"quoted \"key\""{x:[I;1]}[0]`,
+	} {
+		if formatted := mustParsePath(t, text).String(); formatted != text {
+			t.Errorf("Expected %s to format unchanged, got %s", text, formatted)
+		}
+	}
+
+	for _, text := range []string{``, `a.`, `a[`, `a[x]`, `[0]`, `a{b:}`, `a"b"`, `.a`} {
+		if _, err := ParsePath(text); !errors.Is(err, ErrInvalidPath) {
+			t.Errorf("Expected ErrInvalidPath for %q, got %v", text, err)
+		}
+	}
+}
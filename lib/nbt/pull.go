@@ -0,0 +1,223 @@
+package nbt
+
+import (
+	"io"
+)
+
+type TokenKind int
+
+const (
+	// Start of a compound; its children follow as tokens until the matching TokenEnd
+	TokenBeginCompound TokenKind = iota
+	// Start of a list; Length nameless elements follow until the matching TokenEnd
+	TokenBeginList
+	// A tag without children: numbers, strings and the three array types
+	TokenScalar
+	// End of the innermost open compound or list
+	TokenEnd
+)
+
+// Token is a single event produced by PullParser
+type Token struct {
+	Kind TokenKind
+	Type tagTypeByte
+	// Name is empty for list elements and TokenEnd
+	Name string
+	// ElementType and Length are only set for TokenBeginList
+	ElementType tagTypeByte
+	Length      int
+	// Value is only set for TokenScalar and holds the same Go type as the matching Tag struct's Value
+	Value any
+}
+
+type pullFrame struct {
+	isList      bool
+	elementType tagTypeByte
+	length      int
+	remaining   int
+}
+
+// PullParser reads NBT as a sequence of tokens instead of building the whole tree,
+// so large inputs can be scanned for a few fields and uninteresting subtrees skipped.
+type PullParser struct {
+	parser       parser
+	stack        []pullFrame
+	started      bool
+	namelessRoot bool
+}
+
+// NewPullParser returns a PullParser reading Java (big-endian) or Bedrock (little-endian) NBT from data
+func NewPullParser(data []byte, isBedrock bool) *PullParser {
+	return &PullParser{parser: newParser(&sliceSource{data: data}, ParseOptions{Encoding: encodingFor(isBedrock)})}
+}
+
+// NewPullParserReader returns a PullParser reading from a stream
+func NewPullParserReader(r io.Reader, isBedrock bool) *PullParser {
+	return &PullParser{parser: newParser(newReaderSource(r), ParseOptions{Encoding: encodingFor(isBedrock)})}
+}
+
+// SetEncoding switches the NBT variant used for the following tokens
+func (p *PullParser) SetEncoding(encoding Encoding) {
+	p.parser.encoding = encoding
+}
+
+// SetWarningHandler installs a handler for problems that do not stop parsing.
+// Without one, they are ignored.
+func (p *PullParser) SetWarningHandler(handler WarningHandler) {
+	p.parser.warn = handler
+}
+
+// SetLimits replaces DefaultLimits for the rest of the input
+func (p *PullParser) SetLimits(limits Limits) {
+	p.parser.limits = limits
+}
+
+// SetNamelessRoot reads the root tag without a name, see ParseOptions.NamelessRoot
+func (p *PullParser) SetNamelessRoot(namelessRoot bool) {
+	p.namelessRoot = namelessRoot
+}
+
+// Next returns the next token. It returns io.EOF once the root tag has been fully read.
+func (p *PullParser) Next() (Token, error) {
+	if len(p.stack) == 0 {
+		if p.started {
+			return Token{}, io.EOF
+		}
+		p.started = true
+		tagType, name, err := p.parser.readRootHeader(p.namelessRoot)
+		if err != nil {
+			return Token{}, err
+		}
+		if tagType == BTagEnd {
+			return Token{}, newParseValueError("failed to read root tag", 0, tagType, ErrInvalidRoot)
+		}
+		return p.begin(tagType, name)
+	}
+
+	top := &p.stack[len(p.stack)-1]
+	if top.isList {
+		if top.remaining == 0 {
+			p.pop()
+			return Token{Kind: TokenEnd, Type: BTagEnd}, nil
+		}
+		p.parser.pushIndex(top.length - top.remaining)
+		top.remaining--
+		return p.begin(top.elementType, "")
+	}
+
+	tagType, name, err := p.parser.readHeader()
+	if err != nil {
+		return Token{}, err
+	}
+	if tagType == BTagEnd {
+		p.pop()
+		return Token{Kind: TokenEnd, Type: BTagEnd}, nil
+	}
+	p.parser.pushName(name)
+	return p.begin(tagType, name)
+}
+
+// pop closes the innermost container. Every container but the root owns a segment of the parser's path.
+func (p *PullParser) pop() {
+	p.stack = p.stack[:len(p.stack)-1]
+	if len(p.stack) > 0 {
+		p.parser.popPath()
+	}
+}
+
+// begin reads the part of a tag following its header and produces its token.
+// The caller has already pushed the tag's path segment, unless it is the root.
+func (p *PullParser) begin(tagType tagTypeByte, name string) (Token, error) {
+	if tagType == BTagCompound || tagType == BTagList {
+		p.parser.depth = len(p.stack)
+		if err := p.parser.enter(tagType); err != nil {
+			return Token{}, err
+		}
+	}
+	switch tagType {
+	case BTagCompound:
+		p.stack = append(p.stack, pullFrame{})
+		return Token{Kind: TokenBeginCompound, Type: tagType, Name: name}, nil
+	case BTagList:
+		elementType, length, err := p.parser.readListHeader()
+		if err != nil {
+			return Token{}, err
+		}
+		p.stack = append(p.stack, pullFrame{isList: true, elementType: elementType, length: length, remaining: length})
+		return Token{Kind: TokenBeginList, Type: tagType, Name: name, ElementType: elementType, Length: length}, nil
+	}
+	tag, err := p.parser.readPayload(baseTag{tagType, name})
+	if err != nil {
+		return Token{}, err
+	}
+	if len(p.stack) > 0 {
+		p.parser.popPath()
+	}
+	return Token{Kind: TokenScalar, Type: tagType, Name: name, Value: scalarValue(tag)}, nil
+}
+
+// Skip discards the rest of the innermost open compound or list, including its TokenEnd,
+// without building any of its tags. Calling it right after a begin token skips that whole subtree.
+func (p *PullParser) Skip() error {
+	if len(p.stack) == 0 {
+		return nil
+	}
+	top := p.stack[len(p.stack)-1]
+	p.parser.depth = len(p.stack)
+	if top.isList {
+		for range top.remaining {
+			if err := p.parser.skipPayload(top.elementType); err != nil {
+				return err
+			}
+		}
+		p.pop()
+		return nil
+	}
+	for {
+		skipped, err := p.parser.skipTag()
+		if err != nil {
+			return err
+		}
+		if skipped == BTagEnd {
+			p.pop()
+			return nil
+		}
+	}
+}
+
+// Depth returns the number of compounds and lists currently open
+func (p *PullParser) Depth() int {
+	return len(p.stack)
+}
+
+// InputOffset returns the number of bytes consumed so far
+func (p *PullParser) InputOffset() int64 {
+	return p.parser.src.offset()
+}
+
+func scalarValue(tag NBTTag) any {
+	switch t := tag.(type) {
+	case *TagByte:
+		return t.Value
+	case *TagShort:
+		return t.Value
+	case *TagInt:
+		return t.Value
+	case *TagLong:
+		return t.Value
+	case *TagFloat:
+		return t.Value
+	case *TagDouble:
+		return t.Value
+	case *TagString:
+		return t.Value
+	case *TagByteArray:
+		return t.Value
+	case *TagIntArray:
+		return t.Value
+	case *TagLongArray:
+		return t.Value
+	default:
+		return nil
+	}
+}
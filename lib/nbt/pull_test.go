@@ -0,0 +1,83 @@
+package nbt
+
+import (
+	"bytes"
+	"io"
+	"testing"
+)
+
+func TestPullParserTokens(t *testing.T) {
+	p := NewPullParser(decoderTestData(), false)
+
+	expected := []Token{
+		{Kind: TokenBeginCompound, Type: BTagCompound, Name: "root"},
+		{Kind: TokenScalar, Type: BTagString, Name: "title", Value: "Stream"},
+		{Kind: TokenBeginList, Type: BTagList, Name: "numbers", ElementType: BTagInt, Length: 2},
+		{Kind: TokenScalar, Type: BTagInt, Value: int32(7)},
+		{Kind: TokenScalar, Type: BTagInt, Value: int32(8)},
+		{Kind: TokenEnd, Type: BTagEnd},
+		{Kind: TokenScalar, Type: BTagByteArray, Name: "bytes", Value: []byte{1, 2, 3}},
+		{Kind: TokenEnd, Type: BTagEnd},
+	}
+	for i, want := range expected {
+		got, err := p.Next()
+		if err != nil {
+			t.Fatalf("Token %d: unexpected error %v", i, err)
+		}
+		if got.Kind != want.Kind || got.Type != want.Type || got.Name != want.Name ||
+			got.ElementType != want.ElementType || got.Length != want.Length {
+			t.Errorf("Token %d: expected %+v, got %+v", i, want, got)
+		}
+		if b, ok := want.Value.([]byte); ok {
+			if !bytes.Equal(got.Value.([]byte), b) {
+				t.Errorf("Token %d: expected value %v, got %v", i, want.Value, got.Value)
+			}
+		} else if got.Value != want.Value {
+			t.Errorf("Token %d: expected value %v, got %v", i, want.Value, got.Value)
+		}
+	}
+	if _, err := p.Next(); err != io.EOF {
+		t.Errorf("Expected io.EOF after root, got %v", err)
+	}
+}
+
+func TestPullParserSkip(t *testing.T) {
+	// root { big: [{Slot:1b}, {Slot:2b}], after: 5 }
+	data := []byte{
+		byte(BTagCompound), 0x00, 0x00,
+		byte(BTagList), 0x00, 0x03, 'b', 'i', 'g', byte(BTagCompound), 0x00, 0x00, 0x00, 0x02,
+		byte(BTagByte), 0x00, 0x04, 'S', 'l', 'o', 't', 1, byte(BTagEnd),
+		byte(BTagByte), 0x00, 0x04, 'S', 'l', 'o', 't', 2, byte(BTagEnd),
+		byte(BTagInt), 0x00, 0x05, 'a', 'f', 't', 'e', 'r', 0x00, 0x00, 0x00, 0x05,
+		byte(BTagEnd),
+	}
+
+	for _, p := range []*PullParser{
+		NewPullParser(data, false),
+		NewPullParserReader(bytes.NewReader(data), false),
+	} {
+		if tok, err := p.Next(); err != nil || tok.Kind != TokenBeginCompound {
+			t.Fatalf("Expected root compound, got %+v (%v)", tok, err)
+		}
+		tok, err := p.Next()
+		if err != nil || tok.Kind != TokenBeginList || tok.Name != "big" {
+			t.Fatalf("Expected list 'big', got %+v (%v)", tok, err)
+		}
+		if err := p.Skip(); err != nil {
+			t.Fatalf("Failed to skip list: %v", err)
+		}
+		if p.Depth() != 1 {
+			t.Errorf("Expected depth 1 after skip, got %d", p.Depth())
+		}
+		tok, err = p.Next()
+		if err != nil || tok.Kind != TokenScalar || tok.Name != "after" || tok.Value != int32(5) {
+			t.Fatalf("Expected int 'after' = 5, got %+v (%v)", tok, err)
+		}
+		if tok, err := p.Next(); err != nil || tok.Kind != TokenEnd {
+			t.Fatalf("Expected end of root, got %+v (%v)", tok, err)
+		}
+		if p.InputOffset() != int64(len(data)) {
+			t.Errorf("Expected offset %d, got %d", len(data), p.InputOffset())
+		}
+	}
+}
@@ -0,0 +1,47 @@
+package nbt
+
+import "fmt"
+
+// ParseNBTSequence parses root tags stored back to back, as some Bedrock files and mod formats do,
+// until the data ends. A Bedrock level.dat header before the first tag is skipped.
+//
+// Data that does not parse as another root tag is trailing data, whose length is returned.
+// Under TrailingDataError, the parse error found there is returned along with the tags before it,
+// otherwise the error is passed to the warning handler (with TrailingDataWarn) and parsing stops.
+func ParseNBTSequence(data []byte, opts ParseOptions) ([]NBTTag, int, error) {
+	data, err := decompressData(data, opts)
+	if err != nil {
+		return nil, 0, err
+	}
+	src := &sliceSource{data: data}
+	if opts.Encoding == EncodingBedrock && !opts.NamelessRoot && hasLevelHeader(data) {
+		src.pos = levelHeaderSize
+	}
+
+	var tags []NBTTag
+	for src.pos < len(data) {
+		start := src.pos
+		// limits apply to each tag separately, as with the Decoder
+		p := newParser(src, opts)
+		p.lenient = false
+		tag, err := p.readRoot(opts.NamelessRoot)
+		if err == nil && !p.validRoot(tag) {
+			err = newParseValueError("failed to parse NBT", int64(start), tag.Type(), ErrInvalidRoot)
+		}
+		if err != nil {
+			trailing := len(data) - start
+			switch opts.TrailingData {
+			case TrailingDataError:
+				return tags, trailing, err
+			case TrailingDataWarn:
+				if opts.Warn != nil {
+					message := fmt.Sprintf("%d bytes after the last root tag do not parse", trailing)
+					opts.Warn(newParseArrayError(message, int64(start), err.TagType(), err))
+				}
+			}
+			return tags, trailing, nil
+		}
+		tags = append(tags, tag)
+	}
+	return tags, 0, nil
+}
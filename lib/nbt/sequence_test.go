@@ -0,0 +1,82 @@
+package nbt
+
+import (
+	"bytes"
+	"errors"
+	"goNbt/lib"
+	"testing"
+)
+
+func TestParseNBTSequence(t *testing.T) {
+	root := decoderTestData()
+	data := append(append([]byte{}, root...), root...)
+
+	tags, trailing, err := ParseNBTSequence(data, ParseOptions{})
+	if err != nil {
+		t.Fatalf("Failed to parse sequence: %v", err)
+	}
+	if len(tags) != 2 || trailing != 0 {
+		t.Errorf("Expected 2 tags and no trailing bytes, got %d and %d", len(tags), trailing)
+	}
+
+	data = append(data, byte(BTagCompound), 0)
+	tags, trailing, err = ParseNBTSequence(data, ParseOptions{})
+	if err == nil {
+		t.Errorf("Expected the trailing bytes to fail by default")
+	}
+	if len(tags) != 2 || trailing != 2 {
+		t.Errorf("Expected 2 tags and 2 trailing bytes, got %d and %d", len(tags), trailing)
+	}
+
+	var warnings []TagParseError
+	opts := ParseOptions{
+		TrailingData: TrailingDataWarn,
+		Warn:         func(warning TagParseError) { warnings = append(warnings, warning) },
+	}
+	tags, trailing, err = ParseNBTSequence(data, opts)
+	if err != nil || len(tags) != 2 || trailing != 2 {
+		t.Errorf("Expected 2 tags and 2 trailing bytes without error, got %d, %d and %v", len(tags), trailing, err)
+	}
+	if len(warnings) != 1 || warnings[0].Offset() != int64(2*len(root)) {
+		t.Errorf("Expected one warning at offset %d, got %v", 2*len(root), warnings)
+	}
+}
+
+func TestScalarRoots(t *testing.T) {
+	var data []byte
+	for i := range int32(3) {
+		data = appendTestHeader(data, BTagInt, "n")
+		data = append(data, lib.Int32ToBytes(i, true)...)
+	}
+
+	if _, _, err := ParseNBTSequence(data, ParseOptions{}); !errors.Is(err, ErrInvalidRoot) {
+		t.Errorf("Expected ErrInvalidRoot without AllowScalarRoot, got %v", err)
+	}
+	tags, _, err := ParseNBTSequence(data, ParseOptions{AllowScalarRoot: true})
+	if err != nil {
+		t.Fatalf("Failed to parse scalar roots: %v", err)
+	}
+	for i, tag := range tags {
+		if value := tag.(*TagInt).Value; value != int32(i) {
+			t.Errorf("Expected root %d to hold %d, got %d", i, i, value)
+		}
+	}
+
+	tag, err := ParseNBTWithOptions(data[:8], ParseOptions{AllowScalarRoot: true})
+	if err != nil || tag.Type() != BTagInt {
+		t.Errorf("Expected a TAG_Int root, got %v", err)
+	}
+	decoder, _ := NewDecoderWithOptions(bytes.NewReader(data), ParseOptions{AllowScalarRoot: true})
+	for range 3 {
+		if _, err := decoder.Decode(); err != nil {
+			t.Fatalf("Failed to decode a scalar root: %v", err)
+		}
+	}
+	if decoder.More() {
+		t.Errorf("Expected the decoder to reach the end of the input")
+	}
+
+	if _, err := ParseNBTWithOptions([]byte{byte(BTagEnd)}, ParseOptions{AllowScalarRoot: true}); !errors.Is(err, ErrInvalidRoot) {
+		t.Errorf("Expected a TAG_End root to be rejected, got %v", err)
+	}
+}
@@ -442,7 +442,7 @@ func TestSerializeDeserializeRoundTrip(t *testing.T) {
 	}
 
 	// Deserialize
-	deserialized, _, err := separateSingleTag(serialized, 0, true)
+	deserialized, _, err := separateSingleTag(serialized, true)
 	if err != nil {
 		t.Fatalf("Failed to deserialize: %v", err)
 	}
@@ -478,3 +478,121 @@ func TestSerializeDeserializeRoundTrip(t *testing.T) {
 		}
 	}
 }
+
+func TestSerializeNBTBedrock(t *testing.T) {
+	tag := &TagCompound{
+		baseTag: baseTag{tagType: BTagCompound, name: "root"},
+		Value: []NBTTag{
+			&TagShort{baseTag: baseTag{tagType: BTagShort, name: "short"}, Value: -1234},
+			&TagIntArray{baseTag: baseTag{tagType: BTagIntArray, name: "ints"}, Value: []int32{1, 2}},
+			&TagEnd{baseTag: baseTag{tagType: BTagEnd, name: ""}},
+		},
+	}
+
+	data, err := SerializeNBT(tag, true)
+	if err != nil {
+		t.Fatalf("Failed to serialize Bedrock NBT: %v", err)
+	}
+
+	expected := []byte{byte(BTagCompound)}
+	expected = append(expected, lib.UInt16ToBytes(4, false)...)
+	expected = append(expected, []byte("root")...)
+	expected = append(expected, byte(BTagShort))
+	expected = append(expected, lib.UInt16ToBytes(5, false)...)
+	expected = append(expected, []byte("short")...)
+	expected = append(expected, lib.Int16ToBytes(-1234, false)...)
+	expected = append(expected, byte(BTagIntArray))
+	expected = append(expected, lib.UInt16ToBytes(4, false)...)
+	expected = append(expected, []byte("ints")...)
+	expected = append(expected, lib.Int32ToBytes(2, false)...)
+	expected = append(expected, lib.Int32ToBytes(1, false)...)
+	expected = append(expected, lib.Int32ToBytes(2, false)...)
+	expected = append(expected, byte(BTagEnd))
+
+	if !bytes.Equal(data, expected) {
+		t.Errorf("Serialized data mismatch.\nGot:      %v\nExpected: %v", data, expected)
+	}
+}
+
+func TestBedrockRoundTrip(t *testing.T) {
+	// Bedrock level.dat style body: little-endian throughout, including a list of compounds
+	data := []byte{byte(BTagCompound)}
+	data = append(data, lib.UInt16ToBytes(0, false)...)
+
+	data = append(data, byte(BTagLong))
+	data = append(data, lib.UInt16ToBytes(10, false)...)
+	data = append(data, []byte("RandomSeed")...)
+	data = append(data, lib.Int64ToBytes(-6917529027641081856, false)...)
+
+	data = append(data, byte(BTagFloat))
+	data = append(data, lib.UInt16ToBytes(4, false)...)
+	data = append(data, []byte("rain")...)
+	data = append(data, lib.Float32ToBytes(0.25, false)...)
+
+	data = append(data, byte(BTagList))
+	data = append(data, lib.UInt16ToBytes(9, false)...)
+	data = append(data, []byte("abilities")...)
+	data = append(data, byte(BTagCompound))
+	data = append(data, lib.Int32ToBytes(2, false)...)
+	for i := range 2 {
+		data = append(data, byte(BTagByte))
+		data = append(data, lib.UInt16ToBytes(6, false)...)
+		data = append(data, []byte("flying")...)
+		data = append(data, byte(i))
+		data = append(data, byte(BTagEnd))
+	}
+
+	data = append(data, byte(BTagEnd))
+
+	tag, err := ParseNBT(data, true)
+	if err != nil {
+		t.Fatalf("Failed to parse Bedrock NBT: %v", err)
+	}
+
+	serialized, serializeErr := SerializeNBT(tag, true)
+	if serializeErr != nil {
+		t.Fatalf("Failed to serialize Bedrock NBT: %v", serializeErr)
+	}
+	if !bytes.Equal(serialized, data) {
+		t.Errorf("Bedrock round trip mismatch.\nGot:      % x\nExpected: % x", serialized, data)
+	}
+}
+
+func TestSerializeNBTNamelessRoot(t *testing.T) {
+	tag := &TagCompound{
+		baseTag: baseTag{tagType: BTagCompound, name: "ignored"},
+		Value: []NBTTag{
+			&TagByte{baseTag: baseTag{tagType: BTagByte, name: "b"}, Value: 1},
+			&TagEnd{baseTag: baseTag{tagType: BTagEnd, name: ""}},
+		},
+	}
+
+	data, err := SerializeNBTNamelessRoot(tag, EncodingJava)
+	if err != nil {
+		t.Fatalf("Failed to serialize nameless root: %v", err)
+	}
+	expected := []byte{byte(BTagCompound), byte(BTagByte), 0x00, 0x01, 'b', 1, byte(BTagEnd)}
+	if !bytes.Equal(data, expected) {
+		t.Errorf("Serialized data mismatch.\nGot:      %v\nExpected: %v", data, expected)
+	}
+
+	var buf bytes.Buffer
+	encoder := NewEncoder(&buf, false)
+	encoder.SetNamelessRoot(true)
+	if err := encoder.Encode(tag); err != nil {
+		t.Fatalf("Failed to encode nameless root: %v", err)
+	}
+	if !bytes.Equal(buf.Bytes(), expected) {
+		t.Errorf("Encoded data mismatch.\nGot:      %v\nExpected: %v", buf.Bytes(), expected)
+	}
+
+	decoder := NewDecoder(&buf, false)
+	decoder.SetNamelessRoot(true)
+	decoded, err := decoder.Decode()
+	if err != nil {
+		t.Fatalf("Failed to decode nameless root: %v", err)
+	}
+	if len(decoded.(*TagCompound).Value) != 2 {
+		t.Errorf("Expected 2 children (including TagEnd), got %d", len(decoded.(*TagCompound).Value))
+	}
+}
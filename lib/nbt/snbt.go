@@ -0,0 +1,372 @@
+package nbt
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// ErrInvalidSNBT is wrapped by errors for text that is not valid SNBT
+var ErrInvalidSNBT = errors.New("invalid SNBT")
+
+// ParseSNBT parses stringified NBT, the text format of Minecraft commands, such as
+// {id:"minecraft:stone",Count:1b,tag:{Damage:0}}.
+// The returned tag has an empty name.
+func ParseSNBT(text string) (NBTTag, error) {
+	s := &snbtScanner{text: text}
+	tag, err := s.readValue()
+	if err != nil {
+		return nil, err
+	}
+	s.skipSpace()
+	if s.pos < len(s.text) {
+		return nil, s.errorf("unexpected %q after the value", s.text[s.pos:])
+	}
+	return tag, nil
+}
+
+// FormatSNBT returns tag as stringified NBT, without its name
+func FormatSNBT(tag NBTTag) string {
+	var b strings.Builder
+	writeSNBT(&b, tag)
+	return b.String()
+}
+
+func writeSNBT(b *strings.Builder, tag NBTTag) {
+	switch t := tag.(type) {
+	case *TagByte:
+		b.WriteString(strconv.Itoa(int(int8(t.Value))))
+		b.WriteByte('b')
+	case *TagShort:
+		b.WriteString(strconv.Itoa(int(t.Value)))
+		b.WriteByte('s')
+	case *TagInt:
+		b.WriteString(strconv.Itoa(int(t.Value)))
+	case *TagLong:
+		b.WriteString(strconv.FormatInt(t.Value, 10))
+		b.WriteByte('L')
+	case *TagFloat:
+		b.WriteString(strconv.FormatFloat(float64(t.Value), 'g', -1, 32))
+		b.WriteByte('f')
+	case *TagDouble:
+		b.WriteString(strconv.FormatFloat(t.Value, 'g', -1, 64))
+		b.WriteByte('d')
+	case *TagString:
+		b.WriteString(quoteSNBT(t.Value))
+	case *TagByteArray:
+		b.WriteString("[B;")
+		for i, value := range t.Value {
+			if i > 0 {
+				b.WriteByte(',')
+			}
+			b.WriteString(strconv.Itoa(int(int8(value))))
+			b.WriteByte('b')
+		}
+		b.WriteByte(']')
+	case *TagIntArray:
+		b.WriteString("[I;")
+		for i, value := range t.Value {
+			if i > 0 {
+				b.WriteByte(',')
+			}
+			b.WriteString(strconv.Itoa(int(value)))
+		}
+		b.WriteByte(']')
+	case *TagLongArray:
+		b.WriteString("[L;")
+		for i, value := range t.Value {
+			if i > 0 {
+				b.WriteByte(',')
+			}
+			b.WriteString(strconv.FormatInt(value, 10))
+			b.WriteByte('L')
+		}
+		b.WriteByte(']')
+	case *TagList:
+		b.WriteByte('[')
+		for i, item := range t.Value {
+			if i > 0 {
+				b.WriteByte(',')
+			}
+			writeSNBT(b, item)
+		}
+		b.WriteByte(']')
+	case *TagCompound:
+		b.WriteByte('{')
+		first := true
+		for name, child := range t.All() {
+			if !first {
+				b.WriteByte(',')
+			}
+			first = false
+			b.WriteString(formatSNBTKey(name))
+			b.WriteByte(':')
+			writeSNBT(b, child)
+		}
+		b.WriteByte('}')
+	}
+}
+
+// formatSNBTKey quotes compound keys that cannot be written bare
+func formatSNBTKey(name string) string {
+	if name == "" {
+		return `""`
+	}
+	for _, r := range name {
+		if !isUnquotedSNBTChar(r) {
+			return quoteSNBT(name)
+		}
+	}
+	return name
+}
+
+// quoteSNBT puts s in double quotes, escaping only backslashes and double quotes as Minecraft does
+func quoteSNBT(s string) string {
+	var b strings.Builder
+	b.WriteByte('"')
+	for _, r := range s {
+		if r == '"' || r == '\\' {
+			b.WriteByte('\\')
+		}
+		b.WriteRune(r)
+	}
+	b.WriteByte('"')
+	return b.String()
+}
+
+func isUnquotedSNBTChar(r rune) bool {
+	return isPathKeyChar(r) || r == '.'
+}
+
+// snbtScanner reads SNBT, and also NBT paths, which embed SNBT compounds as filters
+type snbtScanner struct {
+	text string
+	pos  int
+}
+
+func (s *snbtScanner) errorf(format string, args ...any) error {
+	return fmt.Errorf("%w: %s at position %d", ErrInvalidSNBT, fmt.Sprintf(format, args...), s.pos)
+}
+
+func (s *snbtScanner) skipSpace() {
+	for s.pos < len(s.text) && strings.IndexByte(" \t\r\n", s.text[s.pos]) >= 0 {
+		s.pos++
+	}
+}
+
+// peek returns the next byte after any whitespace, or 0 at the end of the text
+func (s *snbtScanner) peek() byte {
+	s.skipSpace()
+	if s.pos >= len(s.text) {
+		return 0
+	}
+	return s.text[s.pos]
+}
+
+func (s *snbtScanner) expect(c byte) error {
+	if s.peek() != c {
+		if s.pos >= len(s.text) {
+			return s.errorf("expected %q but the text ended", c)
+		}
+		return s.errorf("expected %q, got %q", c, s.text[s.pos])
+	}
+	s.pos++
+	return nil
+}
+
+// readQuoted reads a string in single or double quotes, in which a backslash escapes the next character
+func (s *snbtScanner) readQuoted() (string, error) {
+	quote := s.text[s.pos]
+	s.pos++
+	var b strings.Builder
+	for s.pos < len(s.text) {
+		c := s.text[s.pos]
+		s.pos++
+		switch c {
+		case quote:
+			return b.String(), nil
+		case '\\':
+			if s.pos >= len(s.text) {
+				return "", s.errorf("unterminated escape")
+			}
+			b.WriteByte(s.text[s.pos])
+			s.pos++
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return "", s.errorf("unterminated string")
+}
+
+// readUnquoted reads the longest run of bytes accepted by allowed
+func (s *snbtScanner) readUnquoted(allowed func(rune) bool) string {
+	start := s.pos
+	for s.pos < len(s.text) && allowed(rune(s.text[s.pos])) {
+		s.pos++
+	}
+	return s.text[start:s.pos]
+}
+
+// readKey reads a compound key, quoted or bare
+func (s *snbtScanner) readKey() (string, error) {
+	switch c := s.peek(); {
+	case c == '"' || c == '\'':
+		return s.readQuoted()
+	case c != 0 && isUnquotedSNBTChar(rune(c)):
+		return s.readUnquoted(isUnquotedSNBTChar), nil
+	}
+	return "", s.errorf("expected a key")
+}
+
+func (s *snbtScanner) readValue() (NBTTag, error) {
+	switch c := s.peek(); {
+	case c == '{':
+		return s.readCompound()
+	case c == '[':
+		return s.readListOrArray()
+	case c == '"' || c == '\'':
+		value, err := s.readQuoted()
+		if err != nil {
+			return nil, err
+		}
+		return NewString("", value), nil
+	case c != 0 && isUnquotedSNBTChar(rune(c)):
+		return parseSNBTScalar(s.readUnquoted(isUnquotedSNBTChar)), nil
+	case c == 0:
+		return nil, s.errorf("expected a value but the text ended")
+	default:
+		return nil, s.errorf("unexpected %q", c)
+	}
+}
+
+func (s *snbtScanner) readCompound() (*TagCompound, error) {
+	if err := s.expect('{'); err != nil {
+		return nil, err
+	}
+	compound := NewCompound("")
+	if s.peek() == '}' {
+		s.pos++
+		return compound, nil
+	}
+	for {
+		name, err := s.readKey()
+		if err != nil {
+			return nil, err
+		}
+		if err := s.expect(':'); err != nil {
+			return nil, err
+		}
+		value, err := s.readValue()
+		if err != nil {
+			return nil, err
+		}
+		value.SetName(name)
+		compound.Set(value)
+		if s.peek() != ',' {
+			return compound, s.expect('}')
+		}
+		s.pos++
+	}
+}
+
+func (s *snbtScanner) readListOrArray() (NBTTag, error) {
+	if err := s.expect('['); err != nil {
+		return nil, err
+	}
+	arrayType := BTagEnd
+	if s.pos+1 < len(s.text) && s.text[s.pos+1] == ';' {
+		switch s.text[s.pos] {
+		case 'B':
+			arrayType = BTagByteArray
+		case 'I':
+			arrayType = BTagIntArray
+		case 'L':
+			arrayType = BTagLongArray
+		default:
+			return nil, s.errorf("unknown array type %q", s.text[s.pos])
+		}
+		s.pos += 2
+	}
+
+	list := NewList("", BTagEnd)
+	if s.peek() == ']' {
+		s.pos++
+	} else {
+		for {
+			start := s.pos
+			item, err := s.readValue()
+			if err != nil {
+				return nil, err
+			}
+			if err := list.Append(item); err != nil {
+				s.pos = start
+				return nil, s.errorf("%s", err)
+			}
+			if s.peek() != ',' {
+				if err := s.expect(']'); err != nil {
+					return nil, err
+				}
+				break
+			}
+			s.pos++
+		}
+	}
+	if arrayType == BTagEnd {
+		return list, nil
+	}
+
+	elementType := arrayElementType(arrayType)
+	if len(list.Value) > 0 && (list.ElementType < BTagByte || list.ElementType > elementType) {
+		return nil, s.errorf("%s cannot hold %s", TagName[arrayType], TagName[list.ElementType])
+	}
+	values, _ := integerValues(list)
+	return newIntegerArray("", arrayType, values), nil
+}
+
+// parseSNBTScalar turns a bare word into a number, a boolean byte, or else a string
+func parseSNBTScalar(word string) NBTTag {
+	switch word {
+	case "true":
+		return NewByte("", 1)
+	case "false":
+		return NewByte("", 0)
+	}
+	if c := word[0]; !(c >= '0' && c <= '9' || c == '-' || c == '+' || c == '.') {
+		return NewString("", word)
+	}
+	last := len(word) - 1
+	number := word[:last]
+	switch word[last] {
+	case 'b', 'B':
+		if i, err := strconv.ParseInt(number, 10, 8); err == nil {
+			return NewByte("", byte(i))
+		}
+	case 's', 'S':
+		if i, err := strconv.ParseInt(number, 10, 16); err == nil {
+			return NewShort("", int16(i))
+		}
+	case 'l', 'L':
+		if i, err := strconv.ParseInt(number, 10, 64); err == nil {
+			return NewLong("", i)
+		}
+	case 'f', 'F':
+		if f, err := strconv.ParseFloat(number, 32); err == nil && !math.IsInf(f, 0) {
+			return NewFloat("", float32(f))
+		}
+	case 'd', 'D':
+		if f, err := strconv.ParseFloat(number, 64); err == nil && !math.IsInf(f, 0) {
+			return NewDouble("", f)
+		}
+	}
+	if i, err := strconv.ParseInt(word, 10, 32); err == nil {
+		return NewInt("", int32(i))
+	}
+	if strings.ContainsAny(word, ".eE") {
+		if f, err := strconv.ParseFloat(word, 64); err == nil && !math.IsInf(f, 0) {
+			return NewDouble("", f)
+		}
+	}
+	return NewString("", word)
+}
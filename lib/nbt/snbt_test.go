@@ -0,0 +1,97 @@
+package nbt
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParseSNBT(t *testing.T) {
+	tag, err := ParseSNBT(`{id: "minecraft:stone", Count: 1b, 'single quoted': 'it\'s', tag: {Damage: 0s, Lore: ["a", "b"]},
+		Pos: [1.5d, 2.0, -3e2], Flag: true, Big: 123L, Ratio: 0.5f, Word: stone_slab,
+		Bytes: [B; 1b, -1b], Ints: [I; 1, 2], Longs: [L; 3L], Empty: []}`)
+	if err != nil {
+		t.Fatalf("Failed to parse SNBT: %v", err)
+	}
+	root := tag.(*TagCompound)
+
+	if id, _ := root.GetString("id"); id != "minecraft:stone" {
+		t.Errorf("Expected id minecraft:stone, got %q", id)
+	}
+	if count, ok := root.GetByte("Count"); !ok || count != 1 {
+		t.Errorf("Expected Count 1b, got %d (found: %v)", count, ok)
+	}
+	if quoted, _ := root.GetString("single quoted"); quoted != "it's" {
+		t.Errorf("Expected it's, got %q", quoted)
+	}
+	nested, _ := root.GetCompound("tag")
+	if damage, ok := nested.GetShort("Damage"); !ok || damage != 0 {
+		t.Errorf("Expected Damage 0s, got %d (found: %v)", damage, ok)
+	}
+	if pos, _ := root.GetList("Pos"); pos.ElementType != BTagDouble || pos.Value[2].(*TagDouble).Value != -300 {
+		t.Errorf("Expected Pos as doubles ending in -300, got %v", pos)
+	}
+	if flag, _ := root.GetByte("Flag"); flag != 1 {
+		t.Errorf("Expected true to read as 1b, got %d", flag)
+	}
+	if big, ok := root.GetLong("Big"); !ok || big != 123 {
+		t.Errorf("Expected Big 123L, got %d (found: %v)", big, ok)
+	}
+	if word, _ := root.GetString("Word"); word != "stone_slab" {
+		t.Errorf("Expected an unquoted string, got %q", word)
+	}
+	if bytes, _ := root.GetByteArray("Bytes"); len(bytes) != 2 || bytes[1] != 0xFF {
+		t.Errorf("Expected [B; 1b, -1b], got %v", bytes)
+	}
+	if longs, _ := root.GetLongArray("Longs"); len(longs) != 1 || longs[0] != 3 {
+		t.Errorf("Expected [L; 3L], got %v", longs)
+	}
+	if empty, ok := root.GetList("Empty"); !ok || len(empty.Value) != 0 {
+		t.Errorf("Expected an empty list, got %v", empty)
+	}
+}
+
+func TestFormatSNBTRoundTrip(t *testing.T) {
+	root := NewCompoundBuilder("ignored").
+		Byte("b", 0xFF).
+		Short("s", 2).
+		Int("i", 3).
+		Long("l", 4).
+		Float("f", 0.25).
+		Double("d", 1).
+		String("minecraft:name", `say "hi" \o/`).
+		ByteArray("ba", []byte{1}).
+		IntArray("ia", []int32{}).
+		LongArray("la", []int64{5, 6}).
+		List("list", BTagCompound, NewCompound("", NewInt("x", 1))).
+		Build()
+
+	text := FormatSNBT(root)
+	expected := `{b:-1b,s:2s,i:3,l:4L,f:0.25f,d:1d,"minecraft:name":"say \"hi\" \\o/",ba:[B;1b],ia:[I;],la:[L;5L,6L],list:[{x:1}]}`
+	if text != expected {
+		t.Errorf("Expected %s\ngot      %s", expected, text)
+	}
+	parsed, err := ParseSNBT(text)
+	if err != nil {
+		t.Fatalf("Failed to parse formatted SNBT: %v", err)
+	}
+	if again := FormatSNBT(parsed); again != text {
+		t.Errorf("Round trip mismatch.\nGot:      %s\nExpected: %s", again, text)
+	}
+}
+
+func TestParseSNBTErrors(t *testing.T) {
+	for _, text := range []string{
+		``,
+		`{a:1`,
+		`{a 1}`,
+		`[1, "two"]`,
+		`[B; 1, 2]`,
+		`[X; 1]`,
+		`"unterminated`,
+		`{a:1} extra`,
+	} {
+		if _, err := ParseSNBT(text); !errors.Is(err, ErrInvalidSNBT) {
+			t.Errorf("Expected ErrInvalidSNBT for %q, got %v", text, err)
+		}
+	}
+}
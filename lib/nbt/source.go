@@ -0,0 +1,148 @@
+package nbt
+
+import (
+	"bufio"
+	"io"
+	"slices"
+)
+
+// byteSource is the input the parser pulls bytes from, either an in-memory slice or a stream
+type byteSource interface {
+	// next returns the following n bytes, which are only valid until the next call
+	next(n int) ([]byte, error)
+	// take returns the following n bytes in a slice the caller may keep
+	take(n int) ([]byte, error)
+	readByte() (byte, error)
+	// skip discards the following n bytes without allocating
+	skip(n int) error
+	// offset is the number of bytes consumed so far
+	offset() int64
+}
+
+// sliceSource reads from a byte slice without copying
+type sliceSource struct {
+	data []byte
+	pos  int
+	// copyTaken makes take return copies instead of aliasing data
+	copyTaken bool
+}
+
+func (s *sliceSource) next(n int) ([]byte, error) {
+	if n < 0 || len(s.data)-s.pos < n {
+		return nil, io.ErrUnexpectedEOF
+	}
+	b := s.data[s.pos : s.pos+n]
+	s.pos += n
+	return b, nil
+}
+
+// take aliases the underlying slice, matching what parsePayload has always returned for byte arrays,
+// unless copyTaken is set
+func (s *sliceSource) take(n int) ([]byte, error) {
+	b, err := s.next(n)
+	if err != nil || !s.copyTaken {
+		return b, err
+	}
+	return slices.Clone(b), nil
+}
+
+func (s *sliceSource) readByte() (byte, error) {
+	if s.pos >= len(s.data) {
+		return 0, io.EOF
+	}
+	b := s.data[s.pos]
+	s.pos++
+	return b, nil
+}
+
+func (s *sliceSource) skip(n int) error {
+	_, err := s.next(n)
+	return err
+}
+
+func (s *sliceSource) offset() int64 { return int64(s.pos) }
+
+// takeChunkSize is how much readerSource.take allocates at a time
+const takeChunkSize = 1 << 20
+
+// readerSource reads from a buffered stream
+type readerSource struct {
+	r   *bufio.Reader
+	pos int64
+}
+
+func newReaderSource(r io.Reader) *readerSource {
+	br, ok := r.(*bufio.Reader)
+	if !ok {
+		br = bufio.NewReader(r)
+	}
+	return &readerSource{r: br}
+}
+
+func (s *readerSource) next(n int) ([]byte, error) {
+	if n < 0 {
+		return nil, io.ErrUnexpectedEOF
+	}
+	if n > s.r.Size() {
+		return s.take(n)
+	}
+	// peeked bytes stay valid until the next read, so small reads avoid allocating
+	b, err := s.r.Peek(n)
+	if err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return nil, err
+	}
+	s.r.Discard(n)
+	s.pos += int64(n)
+	return b, nil
+}
+
+func (s *readerSource) take(n int) ([]byte, error) {
+	if n < 0 {
+		return nil, io.ErrUnexpectedEOF
+	}
+	// grow with the data actually read, so a corrupt length cannot allocate more than the stream holds
+	b := make([]byte, 0, min(n, takeChunkSize))
+	for len(b) < n {
+		chunk := min(n-len(b), takeChunkSize)
+		b = slices.Grow(b, chunk)
+		read, err := io.ReadFull(s.r, b[len(b):len(b)+chunk])
+		b = b[:len(b)+read]
+		s.pos += int64(read)
+		if err != nil {
+			if err == io.EOF {
+				err = io.ErrUnexpectedEOF
+			}
+			return nil, err
+		}
+	}
+	return b, nil
+}
+
+func (s *readerSource) readByte() (byte, error) {
+	b, err := s.r.ReadByte()
+	if err != nil {
+		return 0, err
+	}
+	s.pos++
+	return b, nil
+}
+
+func (s *readerSource) skip(n int) error {
+	if n < 0 {
+		return io.ErrUnexpectedEOF
+	}
+	discarded, err := s.r.Discard(n)
+	s.pos += int64(discarded)
+	if err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return err
+	}
+	return nil
+}
+
+func (s *readerSource) offset() int64 { return s.pos }
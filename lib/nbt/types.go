@@ -67,6 +67,20 @@ var TagPayloadLength map[tagTypeByte]int = map[tagTypeByte]int{
 	BTagLongArray: -1, // Variable length
 }
 
+// arrayElementType returns the type of the numbers stored in a TAG_Byte_Array, TAG_Int_Array or TAG_Long_Array
+func arrayElementType(arrayType tagTypeByte) tagTypeByte {
+	switch arrayType {
+	case BTagByteArray:
+		return BTagByte
+	case BTagIntArray:
+		return BTagInt
+	case BTagLongArray:
+		return BTagLong
+	default:
+		return BTagEnd
+	}
+}
+
 // A tag is an individual part of the data tree. The first byte in a tag is the tag type (ID),
 //
 // followed by a two byte big-endian unsigned integer (ushort) for the length of the name,
@@ -81,20 +95,23 @@ var TagPayloadLength map[tagTypeByte]int = map[tagTypeByte]int{
 type NBTTag interface {
 	Type() tagTypeByte
 	Name() string
+	// SetName renames the tag, which only has an effect in a compound or as the root
+	SetName(name string)
+	// DataLength is the size of the payload in the Java encoding, with strings in Modified UTF-8.
+	// The Bedrock encodings differ for strings with NUL or characters outside the BMP,
+	// and the network encoding also for its VarInt numbers and lengths.
 	DataLength() int
-	ZIndex() int
 }
 
 // Base fields common to all tags
 type baseTag struct {
 	tagType tagTypeByte
 	name    string
-	zIndex  int
 }
 
-func (t *baseTag) Type() tagTypeByte { return t.tagType }
-func (t *baseTag) Name() string      { return t.name }
-func (t *baseTag) ZIndex() int       { return t.zIndex }
+func (t *baseTag) Type() tagTypeByte   { return t.tagType }
+func (t *baseTag) Name() string        { return t.name }
+func (t *baseTag) SetName(name string) { t.name = name }
 
 // TagByte represents a single signed byte
 type TagByte struct {
@@ -150,7 +167,7 @@ type TagString struct {
 	Value string
 }
 
-func (t *TagString) DataLength() int { return 2 + len(t.Value) } // 2 bytes for length + string bytes
+func (t *TagString) DataLength() int { return 2 + mutf8Length(t.Value) } // 2 bytes for length + Modified UTF-8 bytes
 
 // TagByteArray represents an array of bytes
 type TagByteArray struct {
@@ -181,6 +198,9 @@ type TagList struct {
 	baseTag
 	ElementType tagTypeByte
 	Value       []NBTTag
+	// Truncated is set by lenient parsing when the input broke off inside the list,
+	// Value then holds the elements read before
+	Truncated bool
 }
 
 func (t *TagList) DataLength() int {
@@ -197,6 +217,11 @@ func (t *TagList) DataLength() int {
 type TagCompound struct {
 	baseTag
 	Value []NBTTag
+	// Truncated is set by lenient parsing when the input broke off inside the compound,
+	// Value then holds the tags read before, followed by a TAG_End
+	Truncated bool
+	// index maps names to positions in Value once Index was called, see compound.go
+	index map[string]int
 }
 
 func (t *TagCompound) DataLength() int {
@@ -0,0 +1,233 @@
+package nbt
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	// SkipChildren is returned by a WalkFunc or TransformFunc to not visit the children of the current tag
+	SkipChildren = errors.New("skip children")
+	// SkipAll is returned by a WalkFunc or TransformFunc to stop the traversal, without Walk or Transform failing
+	SkipAll = errors.New("skip all")
+)
+
+// WalkFunc is called by Walk for each tag, with the tag's NBT path from the root, see ParsePath.
+// The root's path is empty. Any error other than SkipChildren and SkipAll stops the walk and is returned.
+type WalkFunc func(path string, tag NBTTag) error
+
+// Walk calls fn for every tag in the tree, parents before their children.
+// The children of compounds and the elements of lists are visited in order. The elements of byte,
+// int and long arrays are visited as new tags, so changing them does not change the array.
+// TAG_End is never visited, as it is not a value: neither the markers closing parsed compounds
+// nor the element type of an empty list. The tree must not change during the walk, see Transform.
+func Walk(root NBTTag, fn WalkFunc) error {
+	return WalkOrder(root, fn, nil)
+}
+
+// WalkOrder walks the tree as Walk does, calling pre before the children of a tag are visited
+// and post after them. Either may be nil. SkipChildren from post is ignored.
+func WalkOrder(root NBTTag, pre, post WalkFunc) error {
+	w := &walker{pre: pre, post: post}
+	if err := w.walk(root); err != nil && !errors.Is(err, SkipAll) {
+		return err
+	}
+	return nil
+}
+
+type walker struct {
+	pre, post WalkFunc
+	path      []pathSegment
+}
+
+func (w *walker) walk(tag NBTTag) error {
+	path := formatPath(w.path)
+	descend := true
+	if w.pre != nil {
+		if err := w.pre(path, tag); errors.Is(err, SkipChildren) {
+			descend = false
+		} else if err != nil {
+			return err
+		}
+	}
+	if descend {
+		if err := w.walkChildren(tag); err != nil {
+			return err
+		}
+	}
+	if w.post != nil {
+		if err := w.post(path, tag); err != nil && !errors.Is(err, SkipChildren) {
+			return err
+		}
+	}
+	return nil
+}
+
+func (w *walker) walkChildren(tag NBTTag) error {
+	if compound, ok := tag.(*TagCompound); ok {
+		for name, child := range compound.All() {
+			if err := w.walkChild(pathSegment{name, -1}, child); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	for i := range elementCount(tag) {
+		if err := w.walkChild(pathSegment{"", i}, elementAt(tag, i)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (w *walker) walkChild(segment pathSegment, child NBTTag) error {
+	w.path = append(w.path, segment)
+	err := w.walk(child)
+	w.path = w.path[:len(w.path)-1]
+	return err
+}
+
+// TransformFunc is called by Transform for each tag, with its path as for WalkFunc.
+// It returns the tag to keep it, another tag to replace it, or nil to delete it.
+// A replacement in a compound is stored under its own name, so a transform can rename keys,
+// or under the name of the tag it replaces if it has none.
+// The returned tag is kept when the error is SkipChildren or SkipAll, and ignored for any other error.
+type TransformFunc func(path string, tag NBTTag) (NBTTag, error)
+
+// Transform calls fn for every tag in the tree, parents before their children, and applies
+// the replacements and deletions it returns as it goes. The children visited are those of the
+// tag fn returned, so a replacement is transformed in turn. TAG_End markers are kept without being visited.
+//
+// Transform returns the new root, which is nil if fn deleted it. A replacement that does not fit
+// its place, such as a string in a list of ints or a long in an int array, fails with ErrIncompatibleType.
+// The tree keeps the changes made before fn fails, use Clone first to be able to go back.
+func Transform(root NBTTag, fn TransformFunc) (NBTTag, error) {
+	t := &transformer{fn: fn}
+	result, err := t.transform(root)
+	if errors.Is(err, SkipAll) {
+		err = nil
+	}
+	return result, err
+}
+
+type transformer struct {
+	fn   TransformFunc
+	path []pathSegment
+}
+
+// transform returns what replaces tag, and SkipAll to stop
+func (t *transformer) transform(tag NBTTag) (NBTTag, error) {
+	result, err := t.fn(formatPath(t.path), tag)
+	switch {
+	case errors.Is(err, SkipChildren):
+		return result, nil
+	case errors.Is(err, SkipAll):
+		return result, err
+	case err != nil:
+		return tag, err
+	case result == nil:
+		return nil, nil
+	}
+	return result, t.transformChildren(result)
+}
+
+func (t *transformer) transformChildren(tag NBTTag) error {
+	switch tag := tag.(type) {
+	case *TagCompound:
+		return t.transformCompound(tag)
+	case *TagList:
+		return t.transformList(tag)
+	case *TagByteArray, *TagIntArray, *TagLongArray:
+		return t.transformArray(tag)
+	}
+	return nil
+}
+
+// transformChild transforms a child at the path segment
+func (t *transformer) transformChild(segment pathSegment, child NBTTag) (NBTTag, error) {
+	t.path = append(t.path, segment)
+	result, err := t.transform(child)
+	t.path = t.path[:len(t.path)-1]
+	return result, err
+}
+
+func (t *transformer) transformCompound(compound *TagCompound) error {
+	value := make([]NBTTag, 0, len(compound.Value))
+	var err error
+	for _, child := range compound.Value {
+		if err != nil || child.Type() == BTagEnd {
+			// after an error the rest is kept as it is
+			value = append(value, child)
+			continue
+		}
+		var result NBTTag
+		result, err = t.transformChild(pathSegment{child.Name(), -1}, child)
+		if result != nil {
+			if result.Name() == "" {
+				result.SetName(child.Name())
+			}
+			value = append(value, result)
+		}
+	}
+	compound.Value = value
+	if compound.index != nil {
+		compound.Index()
+	}
+	return err
+}
+
+func (t *transformer) transformList(list *TagList) error {
+	value := make([]NBTTag, 0, len(list.Value))
+	var err error
+	for i, item := range list.Value {
+		if err != nil {
+			value = append(value, item)
+			continue
+		}
+		var result NBTTag
+		result, err = t.transformChild(pathSegment{"", i}, item)
+		if result != nil {
+			result.SetName("")
+			value = append(value, result)
+		}
+	}
+
+	elementType := list.ElementType
+	if len(value) > 0 {
+		elementType = value[0].Type()
+	}
+	for i, item := range value {
+		if item.Type() != elementType {
+			return fmt.Errorf("cannot store %s in a list of %s at %s: %w", TagName[item.Type()], TagName[elementType],
+				formatPath(append(t.path, pathSegment{"", i})), ErrIncompatibleType)
+		}
+	}
+	list.ElementType, list.Value = elementType, value
+	return err
+}
+
+func (t *transformer) transformArray(array NBTTag) error {
+	elementType := arrayElementType(array.Type())
+	values, _ := integerValues(array)
+	kept := make([]int64, 0, len(values))
+	var err error
+	for i := range values {
+		if err != nil {
+			kept = append(kept, values[i])
+			continue
+		}
+		var result NBTTag
+		result, err = t.transformChild(pathSegment{"", i}, elementAt(array, i))
+		if result == nil {
+			continue
+		}
+		number, _, isFloat, ok := numericValue(result)
+		if !ok || isFloat || result.Type() > elementType {
+			return fmt.Errorf("cannot store %s in %s at %s: %w", TagName[result.Type()], TagName[array.Type()],
+				formatPath(append(t.path, pathSegment{"", i})), ErrIncompatibleType)
+		}
+		kept = append(kept, number)
+	}
+	assignTag(array, newIntegerArray(array.Name(), array.Type(), kept))
+	return err
+}
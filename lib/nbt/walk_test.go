@@ -0,0 +1,162 @@
+package nbt
+
+import (
+	"bytes"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// walkTestData holds each tag type that Walk visits, all but TAG_End
+func walkTestData(t *testing.T) NBTTag {
+	return mustParseSNBT(t, `{b: 1b, s: 2s, i: 3, l: 4L, f: 5f, d: 6d, str: "x",
+		ba: [B; 1b, -2b], ia: [I; 3], la: [L; 4L], list: [{n: 1}, {n: 2}], c: {n: 3}}`)
+}
+
+func TestWalk(t *testing.T) {
+	var pre, post []string
+	err := WalkOrder(walkTestData(t), func(path string, tag NBTTag) error {
+		pre = append(pre, path+" "+FormatSNBT(tag))
+		return nil
+	}, func(path string, tag NBTTag) error {
+		post = append(post, path)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Failed to walk: %v", err)
+	}
+
+	expected := []string{
+		` {b:1b,s:2s,i:3,l:4L,f:5f,d:6d,str:"x",ba:[B;1b,-2b],ia:[I;3],la:[L;4L],list:[{n:1},{n:2}],c:{n:3}}`,
+		"b 1b", "s 2s", "i 3", "l 4L", "f 5f", "d 6d", `str "x"`,
+		"ba [B;1b,-2b]", "ba[0] 1b", "ba[1] -2b", "ia [I;3]", "ia[0] 3", "la [L;4L]", "la[0] 4L",
+		"list [{n:1},{n:2}]", "list[0] {n:1}", "list[0].n 1", "list[1] {n:2}", "list[1].n 2",
+		"c {n:3}", "c.n 3",
+	}
+	if !slices.Equal(pre, expected) {
+		t.Errorf("Expected pre-order\n%s\ngot\n%s", strings.Join(expected, "\n"), strings.Join(pre, "\n"))
+	}
+	if len(post) != len(pre) || post[len(post)-1] != "" || !slices.Equal(post[7:10], []string{"ba[0]", "ba[1]", "ba"}) {
+		t.Errorf("Expected children before their parents in post-order, got %v", post)
+	}
+}
+
+func TestWalkSkip(t *testing.T) {
+	var paths []string
+	err := Walk(walkTestData(t), func(path string, tag NBTTag) error {
+		paths = append(paths, path)
+		switch path {
+		case "ba", "list":
+			return SkipChildren
+		case "c":
+			return SkipAll
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Failed to walk: %v", err)
+	}
+	expected := []string{"", "b", "s", "i", "l", "f", "d", "str", "ba", "ia", "ia[0]", "la", "la[0]", "list", "c"}
+	if !slices.Equal(paths, expected) {
+		t.Errorf("Expected %v, got %v", expected, paths)
+	}
+
+	stop := errors.New("stop")
+	if err := Walk(walkTestData(t), func(string, NBTTag) error { return stop }); err != stop {
+		t.Errorf("Expected the walk to return the callback's error, got %v", err)
+	}
+}
+
+func TestWalkSkipsEnd(t *testing.T) {
+	data, err := SerializeNBT(mustParseSNBT(t, `{empty: [], c: {n: 1}, e: {}}`), false)
+	if err != nil {
+		t.Fatalf("Failed to serialize: %v", err)
+	}
+	root, parseErr := ParseNBT(data, false)
+	if parseErr != nil {
+		t.Fatalf("Failed to parse NBT: %v", parseErr)
+	}
+	if value := root.(*TagCompound).Value; value[len(value)-1].Type() != BTagEnd {
+		t.Fatalf("Expected the parsed compound to end with a TAG_End marker")
+	}
+
+	var paths []string
+	err = Walk(root, func(path string, tag NBTTag) error {
+		if tag.Type() == BTagEnd {
+			t.Errorf("Expected TAG_End not to be visited, got one at %q", path)
+		}
+		paths = append(paths, path)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Failed to walk: %v", err)
+	}
+	if expected := []string{"", "empty", "c", "c.n", "e"}; !slices.Equal(paths, expected) {
+		t.Errorf("Expected %v, got %v", expected, paths)
+	}
+
+	// Transform keeps the markers it does not visit
+	_, err = Transform(root, func(path string, tag NBTTag) (NBTTag, error) {
+		if tag.Type() == BTagEnd {
+			t.Errorf("Expected TAG_End not to be transformed, got one at %q", path)
+		}
+		return tag, nil
+	})
+	if err != nil {
+		t.Fatalf("Failed to transform: %v", err)
+	}
+	if reserialized, err := SerializeNBT(root, false); err != nil || !bytes.Equal(reserialized, data) {
+		t.Errorf("Expected the transformed tree to serialize as before, got % x, %v", reserialized, err)
+	}
+}
+
+func TestTransform(t *testing.T) {
+	root := walkTestData(t)
+	root.(*TagCompound).Index()
+	result, err := Transform(root, func(path string, tag NBTTag) (NBTTag, error) {
+		switch path {
+		case "b", "ba[0]", "list[0]":
+			return nil, nil
+		case "s":
+			return NewString("renamed", "short"), nil
+		case "ia[0]":
+			return NewByte("", 7), nil
+		case "c":
+			// the replacement is transformed in turn
+			return NewCompound("", NewInt("n", 4), NewInt("m", 5)), nil
+		case "c.m":
+			return NewInt("", 6), SkipAll
+		}
+		return tag, nil
+	})
+	if err != nil {
+		t.Fatalf("Failed to transform: %v", err)
+	}
+	expected := `{renamed:"short",i:3,l:4L,f:5f,d:6d,str:"x",ba:[B;-2b],ia:[I;7],la:[L;4L],list:[{n:2}],c:{n:4,m:6}}`
+	if result != root || FormatSNBT(root) != expected {
+		t.Errorf("Expected %s\ngot      %s", expected, FormatSNBT(result))
+	}
+	if root.(*TagCompound).Get("renamed") == nil || root.(*TagCompound).Get("b") != nil {
+		t.Errorf("Expected the compound index to follow the changes")
+	}
+
+	deleted, err := Transform(root, func(string, NBTTag) (NBTTag, error) { return nil, nil })
+	if deleted != nil || err != nil {
+		t.Errorf("Expected the root to be deleted, got %v, %v", deleted, err)
+	}
+}
+
+func TestTransformIncompatible(t *testing.T) {
+	for _, path := range []string{"list[1]", "ia[0]"} {
+		_, err := Transform(walkTestData(t), func(p string, tag NBTTag) (NBTTag, error) {
+			if p == path {
+				return NewLong("", 1), nil
+			}
+			return tag, nil
+		})
+		if !errors.Is(err, ErrIncompatibleType) || !strings.Contains(err.Error(), "at "+path+":") {
+			t.Errorf("Expected an incompatible type at %s, got %v", path, err)
+		}
+	}
+}
@@ -4,58 +4,610 @@ package main
 
 import (
 	"bufio"
+	"bytes"
 	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
 	"goNbt/lib"
 	"goNbt/lib/nbt"
 	"io"
 	"os"
+	"path/filepath"
+	"slices"
 )
 
+// cliOptions holds the command line flags shared by parsing and serializing
+type cliOptions struct {
+	parse     nbt.ParseOptions
+	serialize nbt.SerializeOptions
+	// all prints every root tag in the input as a JSON array
+	all bool
+	// args are the arguments left after the flags
+	args []string
+	// compressionGiven is set by -compression, even -compression none,
+	// otherwise edited input keeps its compression
+	compressionGiven bool
+	// json prints differences as JSON
+	json bool
+	// color is auto, always or never
+	color       string
+	ignoreOrder bool
+}
+
+// parseFlags parses the flags before the plain arguments. With interleaved, flags may also follow them.
+// "--" ends the flags, and only leaves the plain arguments after it.
+func parseFlags(args []string, interleaved bool) cliOptions {
+	flags := flag.NewFlagSet(os.Args[0], flag.ExitOnError)
+	bedrock := flags.Bool("bedrock", false, "use the Bedrock Edition little-endian encoding")
+	network := flags.Bool("network", false, "use the Bedrock network encoding with VarInts")
+	nameless := flags.Bool("nameless", false, "the root tag has no name, as in the Java protocol since 1.20.2")
+	compression := flags.String("compression", "", "compress written NBT with gzip or zlib, set, remove and patch otherwise keep the input's compression")
+	maxDepth := flags.Int("max-depth", nbt.DefaultLimits.MaxDepth, "maximum nesting of compounds and lists when parsing, 0 for no limit")
+	maxArrayLength := flags.Int("max-array-length", 0, "maximum length of lists and arrays when parsing, 0 for no limit")
+	maxAllocation := flags.Int64("max-allocation", 0, "approximate maximum bytes of parsed data, 0 for no limit")
+	trailing := flags.String("trailing", "error", "data after the root tag when parsing: error, warn or ignore")
+	lenient := flags.Bool("lenient", false, "print what can be salvaged from corrupt input, listing the errors on stderr")
+	all := flags.Bool("all", false, "parse root tags stored back to back until the input ends, printing a JSON array")
+	scalarRoot := flags.Bool("scalar-root", false, "accept root tags other than compounds and lists")
+	jsonOutput := flags.Bool("json", false, "print differences as JSON")
+	color := flags.String("color", "auto", "color differences: auto, always or never")
+	ignoreOrder := flags.Bool("ignore-order", false, "ignore the order of compound keys when comparing")
+
+	flags.Parse(args)
+	var positional []string
+	for interleaved && flags.NArg() > 0 && !endedByDashes(args, flags.Args()) {
+		positional = append(positional, flags.Arg(0))
+		args = flags.Args()[1:]
+		flags.Parse(args)
+	}
+	rest := flags.Args()
+	if !endedByDashes(args, rest) {
+		// a "--" after the first plain argument still only separates them, as in set XpLevel -- -5
+		if i := slices.Index(rest, "--"); i >= 0 {
+			rest = slices.Delete(slices.Clone(rest), i, i+1)
+		}
+	}
+	positional = append(positional, rest...)
+	compressionGiven := false
+	flags.Visit(func(f *flag.Flag) {
+		compressionGiven = compressionGiven || f.Name == "compression"
+	})
+	if *compression == "none" {
+		*compression = ""
+	}
+	if *maxDepth == 0 {
+		// zero Limits would mean the default depth limit
+		*maxDepth = -1
+	}
+
+	encoding := nbt.EncodingJava
+	if *network {
+		encoding = nbt.EncodingBedrockNetwork
+	} else if *bedrock {
+		encoding = nbt.EncodingBedrock
+	}
+	trailingData := nbt.TrailingDataError
+	switch *trailing {
+	case "error":
+	case "warn":
+		trailingData = nbt.TrailingDataWarn
+	case "ignore":
+		trailingData = nbt.TrailingDataIgnore
+	default:
+		panic(fmt.Errorf("unknown -trailing value %q", *trailing))
+	}
+
+	return cliOptions{
+		parse: nbt.ParseOptions{
+			Encoding:        encoding,
+			NamelessRoot:    *nameless,
+			Limits:          nbt.Limits{MaxDepth: *maxDepth, MaxArrayLength: *maxArrayLength, MaxAllocation: *maxAllocation},
+			TrailingData:    trailingData,
+			Compression:     nbt.CompressionAuto,
+			Lenient:         *lenient,
+			AllowScalarRoot: *scalarRoot,
+			// stdout carries the JSON output, so diagnostics go to stderr
+			Warn: func(warning nbt.TagParseError) {
+				fmt.Fprintln(os.Stderr, "warning:", warning)
+			},
+		},
+		serialize: nbt.SerializeOptions{
+			Encoding:     encoding,
+			NamelessRoot: *nameless,
+			Compression:  nbt.Compression(*compression),
+		},
+		all:              *all,
+		args:             positional,
+		compressionGiven: compressionGiven,
+		json:             *jsonOutput,
+		color:            *color,
+		ignoreOrder:      *ignoreOrder,
+	}
+}
+
+// endedByDashes reports whether the flag package stopped parsing args at a "--", leaving rest
+func endedByDashes(args, rest []string) bool {
+	n := len(args) - len(rest)
+	return n > 0 && args[n-1] == "--"
+}
+
 func main() {
-	if len(os.Args) > 1 && os.Args[1] == "serialize" {
-		reader := bufio.NewReader(os.Stdin)
-		allBytes, err := io.ReadAll(reader)
+	args := os.Args[1:]
+	command := ""
+	if len(args) > 0 {
+		switch args[0] {
+		case "serialize", "get", "set", "remove", "diff", "patch":
+			command = args[0]
+			args = args[1:]
+		}
+	}
+	// the plain arguments of get, set and remove are paths and SNBT values such as -5,
+	// so their flags go first; the compression argument of parsing and serializing may go anywhere
+	options := parseFlags(args, command == "" || command == "serialize")
+
+	switch command {
+	case "serialize", "":
+		// the compression method may also be given as a plain argument, in any position
+		if len(options.args) > 0 {
+			options.serialize.Compression = compressionArg(options.args[len(options.args)-1])
+			options.compressionGiven = true
+		}
+		if command == "serialize" {
+			serialize(options)
+		} else {
+			parse(options)
+		}
+	case "get":
+		get(options)
+	case "set", "remove":
+		edit(command, options)
+	case "diff":
+		diff(options)
+	case "patch":
+		patch(options)
+	}
+}
+
+// compressionArg reads the plain compression argument, where anything but gzip and zlib
+// has always meant uncompressed output, as it does for the C library
+func compressionArg(arg string) nbt.Compression {
+	switch compression := nbt.Compression(arg); compression {
+	case nbt.CompressionGzip, nbt.CompressionZlib:
+		return compression
+	}
+	return nbt.CompressionNone
+}
+
+// serialize reads a JSON tag from stdin and writes it as NBT
+func serialize(options cliOptions) {
+	reader := bufio.NewReader(os.Stdin)
+	allBytes, err := io.ReadAll(reader)
+	if err != nil {
+		panic(err)
+	}
+	var tag nbt.TagCompound
+	err = json.Unmarshal(allBytes, &tag)
+	if err != nil {
+		panic(err)
+	}
+	// expect gzip output to be different, as header may differ (timestamp, comments and etc.)
+	if err := writeTags(os.Stdout, options, storedFormat{}, []nbt.NBTTag{&tag}); err != nil {
+		panic(err)
+	}
+}
+
+// parse reads NBT from stdin and prints it as JSON
+func parse(options cliOptions) {
+	tags, partial := readTags(os.Stdin, options)
+	var output any = tags
+	if !options.all {
+		output = tags[0]
+	}
+	printJSON(output)
+	if partial {
+		os.Exit(1)
+	}
+}
+
+// get prints the tags an NBT path selects in the input as a JSON array
+//
+//	nbt get 'Inventory[{Slot:0b}].id' < player.dat
+func get(options cliOptions) {
+	path := pathArg(options, 1)
+	tags, partial := readTags(os.Stdin, options)
+	var matches []nbt.NBTTag
+	for _, tag := range tags {
+		matches = append(matches, path.Get(tag)...)
+	}
+	if len(matches) == 0 {
+		fmt.Fprintln(os.Stderr, "error: found no elements matching", path)
+		os.Exit(1)
+	}
+	printJSON(matches)
+	if partial {
+		os.Exit(1)
+	}
+}
+
+// edit changes the input at an NBT path and writes the NBT back out,
+// stored as the input was unless -compression is given
+//
+//	nbt set 'Data.Player.XpLevel' 30 < level.dat > edited.dat
+//	nbt remove 'Level.Entities[]' < chunk.nbt > cleared.nbt
+func edit(command string, options cliOptions) {
+	argCount := 1
+	if command == "set" {
+		argCount = 2
+	}
+	path := pathArg(options, argCount)
+	var value nbt.NBTTag
+	if command == "set" {
+		var err error
+		value, err = nbt.ParseSNBT(options.args[1])
 		if err != nil {
 			panic(err)
 		}
-		var tag nbt.TagCompound
-		err = json.Unmarshal(allBytes, &tag)
+	}
+	tags, format := readEditable(options)
+	count := 0
+	for _, tag := range tags {
+		var n int
+		var err error
+		if command == "set" {
+			n, err = path.Set(tag, value)
+		} else {
+			n, err = path.Remove(tag)
+		}
 		if err != nil {
 			panic(err)
 		}
-		serializedBytes, err := nbt.SerializeTag(&tag, false)
+		count += n
+	}
+	if count == 0 {
+		fmt.Fprintln(os.Stderr, "error: found no elements matching", path)
+		os.Exit(1)
+	}
+	if err := writeTags(os.Stdout, options, format, tags); err != nil {
+		panic(err)
+	}
+}
+
+// diff compares two NBT files, exiting with 1 if they differ
+//
+//	nbt diff level.dat level.dat_old
+func diff(options cliOptions) {
+	if len(options.args) != 2 {
+		fmt.Fprintln(os.Stderr, "usage: nbt diff [-json] [-color auto|always|never] [-ignore-order] <a> <b>")
+		os.Exit(2)
+	}
+	var roots [2]nbt.NBTTag
+	for i, name := range options.args {
+		data, err := os.ReadFile(name)
 		if err != nil {
 			panic(err)
 		}
-		if len(os.Args) > 2 {
-			switch os.Args[2] {
-			// expect gzip output to be different, as header may differ (timestamp, comments and etc.)
-			case "gzip":
-				serializedBytes, err = lib.ZipToGzip(serializedBytes)
-				if err != nil {
-					panic(err)
-				}
-			case "zlib":
-				serializedBytes, err = lib.ZipToZlib(serializedBytes)
-				if err != nil {
-					panic(err)
-				}
+		roots[i], err = nbt.ParseNBTWithOptions(data, options.parse)
+		if err != nil {
+			panic(fmt.Errorf("%s: %w", name, err))
+		}
+	}
+
+	differences := nbt.Diff(roots[0], roots[1], nbt.EqualOptions{IgnoreKeyOrder: options.ignoreOrder})
+	if options.json {
+		printJSON(differences)
+	} else {
+		printDifferences(differences, useColor(options.color))
+	}
+	if len(differences) > 0 {
+		os.Exit(1)
+	}
+}
+
+// maxDiffValue is how much of a value a difference line shows
+const maxDiffValue = 120
+
+func printDifferences(differences []nbt.Difference, color bool) {
+	paint := func(code, text string) string {
+		if !color {
+			return text
+		}
+		return "\x1b[" + code + "m" + text + "\x1b[0m"
+	}
+	for _, difference := range differences {
+		path := difference.Path
+		if path == "" {
+			path = "(root)"
+		}
+		switch difference.Kind {
+		case nbt.DiffAdded:
+			fmt.Println(paint("32", "+ "+path+": "+diffValue(difference.New)))
+		case nbt.DiffRemoved:
+			fmt.Println(paint("31", "- "+path+": "+diffValue(difference.Old)))
+		case nbt.DiffChanged:
+			fmt.Println(paint("33", "~ "+path+": "+diffValue(difference.Old)+" -> "+diffValue(difference.New)))
+		case nbt.DiffReordered:
+			fmt.Println(paint("36", "~ "+path+": keys reordered"))
+		}
+	}
+}
+
+// diffValue shows a value as SNBT with its type, shortened to maxDiffValue characters
+func diffValue(tag nbt.NBTTag) string {
+	text := []rune(nbt.FormatSNBT(tag))
+	if len(text) > maxDiffValue {
+		text = append(text[:maxDiffValue-1], '…')
+	}
+	return string(text) + " (" + nbt.TagName[tag.Type()] + ")"
+}
+
+func useColor(mode string) bool {
+	switch mode {
+	case "always":
+		return true
+	case "never":
+		return false
+	case "auto":
+		if os.Getenv("NO_COLOR") != "" {
+			return false
+		}
+		info, err := os.Stdout.Stat()
+		return err == nil && info.Mode()&os.ModeCharDevice != 0
+	default:
+		panic(fmt.Errorf("unknown -color value %q", mode))
+	}
+}
+
+// patch applies a JSON patch to NBT files in place, or to stdin if no files are given.
+// A file the patch fails on is left as it was, and the other files are still patched.
+//
+//	nbt patch give-elytra.json playerdata/*.dat
+func patch(options cliOptions) {
+	if len(options.args) == 0 {
+		fmt.Fprintln(os.Stderr, "usage: nbt patch <patch.json> [files...]")
+		os.Exit(2)
+	}
+	patchData, err := os.ReadFile(options.args[0])
+	if err != nil {
+		panic(err)
+	}
+	operations, err := nbt.ParsePatch(patchData)
+	if err != nil {
+		panic(err)
+	}
+
+	files := options.args[1:]
+	if len(files) == 0 {
+		tags, format := readEditable(options)
+		for _, tag := range tags {
+			if err := nbt.ApplyPatch(tag, operations); err != nil {
+				fmt.Fprintln(os.Stderr, "error:", err)
+				os.Exit(1)
 			}
 		}
-		os.Stdout.Write(serializedBytes)
+		if err := writeTags(os.Stdout, options, format, tags); err != nil {
+			panic(err)
+		}
 		return
 	}
-	allBytes, err := lib.UnzipReader(os.Stdin)
 
+	failed := 0
+	for _, name := range files {
+		if err := patchFile(name, operations, options); err != nil {
+			fmt.Fprintf(os.Stderr, "error: %s: %v\n", name, err)
+			failed++
+		}
+	}
+	if failed > 0 {
+		fmt.Fprintf(os.Stderr, "failed to patch %d of %d files\n", failed, len(files))
+		os.Exit(1)
+	}
+}
+
+// patchFile applies the patch to one file, keeping its compression unless -compression asks for one,
+// and the header of a Bedrock level.dat. The result goes to a temporary file renamed over the original,
+// so an interrupted run leaves no half-written file.
+func patchFile(name string, operations nbt.Patch, options cliOptions) error {
+	file, err := os.Open(name)
+	if err != nil {
+		return err
+	}
+	data, format, err := readStored(file, options)
+	file.Close()
+	if err != nil {
+		return err
+	}
+	options.parse.Compression = nbt.CompressionNone
+	tag, err := nbt.ParseNBTWithOptions(data, options.parse)
+	if err != nil {
+		return err
+	}
+	if err := nbt.ApplyPatch(tag, operations); err != nil {
+		return err
+	}
+	var output bytes.Buffer
+	if err := writeTags(&output, options, format, []nbt.NBTTag{tag}); err != nil {
+		return err
+	}
+
+	info, err := os.Stat(name)
+	if err != nil {
+		return err
+	}
+	temp, err := os.CreateTemp(filepath.Dir(name), filepath.Base(name)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(temp.Name())
+	_, err = temp.Write(output.Bytes())
+	if closeErr := temp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Chmod(temp.Name(), info.Mode().Perm())
+	}
+	if err != nil {
+		return err
+	}
+	return os.Rename(temp.Name(), name)
+}
+
+// detectCompression recognizes gzip and zlib data by their magic bytes, as CompressionAuto does
+func detectCompression(data []byte) nbt.Compression {
+	switch {
+	case len(data) < 2:
+		return nbt.CompressionNone
+	case data[0] == 0x1f && data[1] == 0x8b:
+		return nbt.CompressionGzip
+	case data[0] == 0x78 && (data[1] == 0x01 || data[1] == 0x5e || data[1] == 0x9c || data[1] == 0xda):
+		return nbt.CompressionZlib
+	}
+	return nbt.CompressionNone
+}
+
+// pathArg parses the first argument as an NBT path, after checking that there are n arguments
+func pathArg(options cliOptions, n int) *nbt.Path {
+	if len(options.args) != n {
+		fmt.Fprintln(os.Stderr, "usage: nbt get <path> | nbt set <path> <snbt value> | nbt remove <path>")
+		os.Exit(2)
+	}
+	path, err := nbt.ParsePath(options.args[0])
 	if err != nil {
 		panic(err)
 	}
+	return path
+}
+
+// storedFormat is how NBT input was stored, so that an edited copy can be written back the same way
+type storedFormat struct {
+	compression nbt.Compression
+	// levelHeader is set for a Bedrock level.dat, whose header is written back with storageVersion
+	levelHeader    bool
+	storageVersion int32
+}
 
-	tag, err := nbt.ParseNBT(allBytes, false)
+// readStored reads all of r and returns it decompressed, along with how it was stored
+func readStored(r io.Reader, options cliOptions) ([]byte, storedFormat, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, storedFormat{}, err
+	}
+	format := storedFormat{compression: detectCompression(data)}
+	if format.compression != nbt.CompressionNone {
+		unzipped, err := lib.NewUnzipReader(bytes.NewReader(data))
+		if err != nil {
+			return nil, format, err
+		}
+		if data, err = io.ReadAll(unzipped); err != nil {
+			return nil, format, err
+		}
+	}
+	// the parser skips the header in the same case
+	if options.parse.Encoding == nbt.EncodingBedrock && !options.parse.NamelessRoot {
+		format.storageVersion, format.levelHeader = nbt.ReadLevelHeader(data)
+	}
+	return data, format, nil
+}
+
+// readEditable reads the root tags on stdin to write them back changed, along with how they were stored
+func readEditable(options cliOptions) ([]nbt.NBTTag, storedFormat) {
+	data, format, err := readStored(os.Stdin, options)
+	if err != nil {
+		panic(err)
+	}
+	options.parse.Compression = nbt.CompressionNone
+	tags, partial := readTags(bytes.NewReader(data), options)
+	if partial {
+		fmt.Fprintln(os.Stderr, "error: refusing to write back a partial tree")
+		os.Exit(1)
+	}
+	return tags, format
+}
+
+// readTags parses the root tags in r, partial reports that lenient mode salvaged a broken one
+func readTags(r io.Reader, options cliOptions) (tags []nbt.NBTTag, partial bool) {
+	decoder, err := nbt.NewDecoderWithOptions(r, options.parse)
 	if err != nil {
 		panic(err)
 	}
-	jsonTag, err := json.MarshalIndent(tag, "", "  ")
+	// trailing handles data after the last root tag as the -trailing flag asks
+	trailing := func(err error) {
+		switch options.parse.TrailingData {
+		case nbt.TrailingDataError:
+			panic(err)
+		case nbt.TrailingDataWarn:
+			fmt.Fprintln(os.Stderr, "warning:", err)
+		}
+	}
+	for {
+		tag, err := decoder.Decode()
+		if err == io.EOF && len(tags) > 0 {
+			return tags, false
+		}
+		var parseErrors nbt.ParseErrors
+		if errors.As(err, &parseErrors) && tag != nil {
+			// lenient mode salvaged a partial tree, the input cannot be read past it
+			for _, parseErr := range parseErrors {
+				fmt.Fprintln(os.Stderr, "error:", parseErr)
+			}
+			return append(tags, tag), true
+		}
+		if err != nil {
+			if len(tags) == 0 {
+				panic(err)
+			}
+			trailing(err)
+			return tags, false
+		}
+		tags = append(tags, tag)
+		if !options.all {
+			if decoder.More() {
+				trailing(fmt.Errorf("extra data after parsing NBT tag (at offset %d)", decoder.InputOffset()))
+			}
+			return tags, false
+		}
+	}
+}
+
+// writeTags writes the tags to w as NBT, back to back, stored in format unless -compression is given
+func writeTags(w io.Writer, options cliOptions, format storedFormat, tags []nbt.NBTTag) error {
+	serializeOptions := options.serialize
+	if !options.compressionGiven {
+		serializeOptions.Compression = format.compression
+	}
+	if !format.levelHeader {
+		return encodeTags(w, serializeOptions, tags)
+	}
+
+	// the header holds the length of the NBT after it, so that is encoded first
+	compression := serializeOptions.Compression
+	serializeOptions.Compression = nbt.CompressionNone
+	var body bytes.Buffer
+	if err := encodeTags(&body, serializeOptions, tags); err != nil {
+		return err
+	}
+	compressor := lib.NewZipWriter(w, string(compression))
+	if _, err := compressor.Write(nbt.AddLevelHeader(format.storageVersion, body.Bytes())); err != nil {
+		return err
+	}
+	return compressor.Close()
+}
+
+func encodeTags(w io.Writer, options nbt.SerializeOptions, tags []nbt.NBTTag) error {
+	encoder, err := nbt.NewEncoderWithOptions(w, options)
+	if err != nil {
+		return err
+	}
+	for _, tag := range tags {
+		if err := encoder.Encode(tag); err != nil {
+			return err
+		}
+	}
+	return encoder.Close()
+}
+
+func printJSON(output any) {
+	jsonTag, err := json.MarshalIndent(output, "", "  ")
 	if err != nil {
 		panic(err)
 	}
@@ -0,0 +1,187 @@
+//go:build !cshared
+
+package main
+
+import (
+	"bytes"
+	"goNbt/lib"
+	"goNbt/lib/nbt"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+// nestedLists nests depth lists inside a root compound
+func nestedLists(depth int) nbt.NBTTag {
+	var inner nbt.NBTTag = nbt.NewList("", nbt.BTagEnd)
+	for range depth - 1 {
+		inner = nbt.NewList("", nbt.BTagList, inner)
+	}
+	return nbt.NewCompound("", inner)
+}
+
+func TestMaxDepthFlag(t *testing.T) {
+	data, err := nbt.SerializeNBTWithOptions(nestedLists(1000), nbt.SerializeOptions{})
+	if err != nil {
+		t.Fatalf("Failed to serialize: %v", err)
+	}
+	if _, err := nbt.ParseNBTWithOptions(data, parseFlags(nil, false).parse); err == nil {
+		t.Errorf("Expected the default depth limit to reject 1000 levels")
+	}
+	if _, err := nbt.ParseNBTWithOptions(data, parseFlags([]string{"-max-depth", "0"}, false).parse); err != nil {
+		t.Errorf("Expected -max-depth 0 to turn off the depth limit, got %v", err)
+	}
+	if _, err := nbt.ParseNBTWithOptions(data, parseFlags([]string{"-max-depth", "8", "-max-array-length", "10"}, false).parse); err == nil {
+		t.Errorf("Expected -max-depth 8 to reject 1000 levels")
+	}
+}
+
+func TestParseFlagsNegativeValues(t *testing.T) {
+	tests := []struct {
+		args        []string
+		interleaved bool
+		expected    []string
+	}{
+		{[]string{"Health", "-5"}, false, []string{"Health", "-5"}},
+		{[]string{"-bedrock", "Health", "-1.0f"}, false, []string{"Health", "-1.0f"}},
+		{[]string{"--", "XpLevel", "-5"}, false, []string{"XpLevel", "-5"}},
+		{[]string{"XpLevel", "--", "-5"}, false, []string{"XpLevel", "-5"}},
+		{[]string{"gzip", "-bedrock"}, true, []string{"gzip"}},
+		{[]string{"-bedrock", "--", "-x"}, true, []string{"-x"}},
+	}
+	for _, test := range tests {
+		options := parseFlags(test.args, test.interleaved)
+		if !slices.Equal(options.args, test.expected) {
+			t.Errorf("Expected %q to leave %q, got %q", test.args, test.expected, options.args)
+		}
+		if slices.Contains(test.args, "-bedrock") && options.parse.Encoding != nbt.EncodingBedrock {
+			t.Errorf("Expected -bedrock in %q to be parsed as a flag", test.args)
+		}
+	}
+}
+
+// levelDat returns a Bedrock level.dat holding XpLevel, with its header
+func levelDat(t *testing.T, storageVersion int32) []byte {
+	t.Helper()
+	body, err := nbt.SerializeNBTWithOptions(nbt.NewCompound("", nbt.NewInt("XpLevel", 1)), nbt.SerializeOptions{Encoding: nbt.EncodingBedrock})
+	if err != nil {
+		t.Fatalf("Failed to serialize: %v", err)
+	}
+	return nbt.AddLevelHeader(storageVersion, body)
+}
+
+// checkLevelDat checks that data is a level.dat with the storage version and XpLevel
+func checkLevelDat(t *testing.T, data []byte, storageVersion int32, xpLevel int32) {
+	t.Helper()
+	version, ok := nbt.ReadLevelHeader(data)
+	if !ok || version != storageVersion {
+		t.Fatalf("Expected a level.dat header with version %d, got % x", storageVersion, data[:min(len(data), 8)])
+	}
+	level, err := nbt.ParseBedrockLevelDat(data)
+	if err != nil {
+		t.Fatalf("Failed to parse level.dat: %v", err)
+	}
+	if value, _ := level.Root.(*nbt.TagCompound).GetInt("XpLevel"); value != xpLevel {
+		t.Errorf("Expected XpLevel %d, got %d", xpLevel, value)
+	}
+}
+
+func TestEditKeepsStoredFormat(t *testing.T) {
+	options := parseFlags([]string{"-bedrock"}, false)
+	var compressed bytes.Buffer
+	zip := lib.NewZipWriter(&compressed, "gzip")
+	zip.Write(levelDat(t, 10))
+	zip.Close()
+
+	data, format, err := readStored(&compressed, options)
+	if err != nil {
+		t.Fatalf("Failed to read: %v", err)
+	}
+	if format.compression != nbt.CompressionGzip || !format.levelHeader || format.storageVersion != 10 {
+		t.Fatalf("Expected a gzip level.dat with version 10, got %+v", format)
+	}
+	options.parse.Compression = nbt.CompressionNone
+	tags, _ := readTags(bytes.NewReader(data), options)
+	mustSet(t, tags[0], "XpLevel", nbt.NewInt("", 30))
+
+	var output bytes.Buffer
+	if err := writeTags(&output, options, format, tags); err != nil {
+		t.Fatalf("Failed to write: %v", err)
+	}
+	if detectCompression(output.Bytes()) != nbt.CompressionGzip {
+		t.Fatalf("Expected gzip output, got % x", output.Bytes()[:2])
+	}
+	unzipped, err := lib.UnzipReader(&output)
+	if err != nil {
+		t.Fatalf("Failed to decompress: %v", err)
+	}
+	checkLevelDat(t, unzipped, 10, 30)
+
+	// -compression overrides the input's
+	options = parseFlags([]string{"-bedrock", "-compression", "none"}, false)
+	output.Reset()
+	if err := writeTags(&output, options, format, tags); err != nil {
+		t.Fatalf("Failed to write: %v", err)
+	}
+	checkLevelDat(t, output.Bytes(), 10, 30)
+}
+
+func mustSet(t *testing.T, root nbt.NBTTag, path string, value nbt.NBTTag) {
+	t.Helper()
+	parsed, err := nbt.ParsePath(path)
+	if err != nil {
+		t.Fatalf("Failed to parse path: %v", err)
+	}
+	if n, err := parsed.Set(root, value); n != 1 || err != nil {
+		t.Fatalf("Failed to set %s: %d, %v", path, n, err)
+	}
+}
+
+func TestPatchFileKeepsLevelHeader(t *testing.T) {
+	operations, err := nbt.ParsePatch([]byte(`[{"op": "replace", "path": "XpLevel", "value": "30"}]`))
+	if err != nil {
+		t.Fatalf("Failed to parse patch: %v", err)
+	}
+	for _, compression := range []string{"none", "gzip"} {
+		var stored bytes.Buffer
+		zip := lib.NewZipWriter(&stored, compression)
+		zip.Write(levelDat(t, 10))
+		zip.Close()
+		name := filepath.Join(t.TempDir(), "level.dat")
+		if err := os.WriteFile(name, stored.Bytes(), 0o644); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := patchFile(name, operations, parseFlags([]string{"-bedrock"}, false)); err != nil {
+			t.Fatalf("Failed to patch %s level.dat: %v", compression, err)
+		}
+		data, err := os.ReadFile(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if compression == "gzip" {
+			if detectCompression(data) != nbt.CompressionGzip {
+				t.Fatalf("Expected the patched file to stay gzip, got % x", data[:2])
+			}
+			if data, err = lib.UnzipReader(bytes.NewReader(data)); err != nil {
+				t.Fatalf("Failed to decompress: %v", err)
+			}
+		}
+		checkLevelDat(t, data, 10, 30)
+	}
+}
+
+func TestCompressionArg(t *testing.T) {
+	for arg, expected := range map[string]nbt.Compression{
+		"gzip": nbt.CompressionGzip,
+		"zlib": nbt.CompressionZlib,
+		"none": nbt.CompressionNone,
+		"lz4":  nbt.CompressionNone,
+		"":     nbt.CompressionNone,
+	} {
+		if compression := compressionArg(arg); compression != expected {
+			t.Errorf("Expected %q to select %q, got %q", arg, expected, compression)
+		}
+	}
+}